@@ -0,0 +1,56 @@
+package pgstring
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Count returns COUNT(item); Count("*") counts rows
+func Count(item any) Expr {
+	return call("COUNT", []any{item})
+}
+
+// Sum returns SUM(item)
+func Sum(item any) Expr {
+	return call("SUM", []any{item})
+}
+
+// Avg returns AVG(item)
+func Avg(item any) Expr {
+	return call("AVG", []any{item})
+}
+
+// Min returns MIN(item)
+func Min(item any) Expr {
+	return call("MIN", []any{item})
+}
+
+// Max returns MAX(item)
+func Max(item any) Expr {
+	return call("MAX", []any{item})
+}
+
+// PercentileCont returns the continuous percentile of column, interpolating between values:
+// percentile_cont(0.95) WITHIN GROUP (ORDER BY column)
+func PercentileCont(fraction float64, column string) Expr {
+	return orderedSet("percentile_cont", fraction, column)
+}
+
+// PercentileDisc returns the first value of column at or above the percentile:
+// percentile_disc(0.95) WITHIN GROUP (ORDER BY column)
+func PercentileDisc(fraction float64, column string) Expr {
+	return orderedSet("percentile_disc", fraction, column)
+}
+
+// Mode returns the most frequent value of column: mode() WITHIN GROUP (ORDER BY column)
+func Mode(column string) Expr {
+	return Expr{sql: "mode() WITHIN GROUP (ORDER BY " + column + ")"}
+}
+
+// orderedSet renders a percentile ordered-set aggregate
+func orderedSet(function string, fraction float64, column string) Expr {
+	if fraction < 0 || fraction > 1 {
+		return Expr{err: fmt.Errorf("%s fraction %v is outside 0-1", function, fraction)}
+	}
+	return Expr{sql: function + "(" + strconv.FormatFloat(fraction, 'g', -1, 64) + ") WITHIN GROUP (ORDER BY " + column + ")"}
+}
@@ -0,0 +1,85 @@
+package pgstring
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Deferral is the deferral mode of a unique or foreign key constraint
+type Deferral string
+
+const (
+	NotDeferrable      Deferral = "NOT DEFERRABLE"
+	InitiallyImmediate Deferral = "DEFERRABLE INITIALLY IMMEDIATE"
+	InitiallyDeferred  Deferral = "DEFERRABLE INITIALLY DEFERRED"
+)
+
+// ConstraintMode is the mode set by SetConstraints
+type ConstraintMode string
+
+const (
+	ConstraintsDeferred  ConstraintMode = "DEFERRED"
+	ConstraintsImmediate ConstraintMode = "IMMEDIATE"
+)
+
+// deferralOf returns the deferral requested by a deferrable tag option:
+// db:",deferrable" defers until commit, db:",deferrable=immediate" checks per statement
+// unless deferred with SET CONSTRAINTS
+func deferralOf(options []string) Deferral {
+	if value, ok := optionValue(options, "deferrable"); ok {
+		if strings.EqualFold(value, "immediate") {
+			return InitiallyImmediate
+		}
+		return InitiallyDeferred
+	}
+	if hasOption(options, "deferrable") {
+		return InitiallyDeferred
+	}
+	return ""
+}
+
+// AlterTable creates a new PgString for an ALTER TABLE statement. Actions added with
+// AddUnique, AddForeignKey and AlterConstraint are separated by commas.
+func AlterTable(table string) PgString {
+	auditIdent("AlterTable", table)
+	return PgString{}.with(clause{kind: clauseAlterTable, sql: table, table: table, writes: true})
+}
+
+// AddUnique adds a UNIQUE constraint on columns
+func (pg PgString) AddUnique(name string, columns []string, deferral Deferral) PgString {
+	action := fmt.Sprintf("ADD CONSTRAINT %s UNIQUE (%s)", name, strings.Join(columns, ", "))
+	return pg.with(clause{kind: clauseAlterAction, sql: withDeferral(action, deferral)})
+}
+
+// AddForeignKey adds a FOREIGN KEY constraint from columns to refColumns of refTable
+func (pg PgString) AddForeignKey(name string, columns []string, refTable string, refColumns []string, deferral Deferral) PgString {
+	action := fmt.Sprintf("ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		name, strings.Join(columns, ", "), refTable, strings.Join(refColumns, ", "))
+	return pg.with(clause{kind: clauseAlterAction, sql: withDeferral(action, deferral)})
+}
+
+// AlterConstraint changes the deferral mode of an existing foreign key constraint
+func (pg PgString) AlterConstraint(name string, deferral Deferral) PgString {
+	if deferral == "" {
+		return pg.fail(fmt.Errorf("AlterConstraint %s requires a deferral mode", name))
+	}
+	return pg.with(clause{kind: clauseAlterAction, sql: "ALTER CONSTRAINT " + name + " " + string(deferral)})
+}
+
+// SetConstraints creates a SET CONSTRAINTS statement changing when deferrable constraints
+// are checked in the current transaction. Without names it applies to ALL constraints.
+func SetConstraints(mode ConstraintMode, names ...string) PgString {
+	target := "ALL"
+	if len(names) > 0 {
+		target = strings.Join(names, ", ")
+	}
+	return RawSQL("SET CONSTRAINTS " + target + " " + string(mode))
+}
+
+// withDeferral appends a deferral mode to a constraint definition
+func withDeferral(definition string, deferral Deferral) string {
+	if deferral == "" {
+		return definition
+	}
+	return definition + " " + string(deferral)
+}
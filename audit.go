@@ -0,0 +1,82 @@
+package pgstring
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// AuditFinding describes a builder input that looks like it carries user-controlled SQL
+type AuditFinding struct {
+	// Method is the builder method that received the input, e.g. Where
+	Method string
+	Input  string
+	// Reason says what looked suspicious, e.g. "semicolon"
+	Reason string
+	// Caller names the Go call site, e.g. orders.List (orders.go:42)
+	Caller string
+}
+
+// auditHook holds the hook registered with SetAuditHook
+var auditHook atomic.Pointer[func(AuditFinding)]
+
+// SetAuditHook enables audit mode: hook is called for every identifier or raw SQL fragment
+// passed to a builder that contains quotes, semicolons or comment markers, which usually
+// means a value was concatenated into the SQL instead of bound as an @arg. Findings are
+// reported, not rejected, to help security reviews; nil disables audit mode.
+func SetAuditHook(hook func(AuditFinding)) {
+	if hook == nil {
+		auditHook.Store(nil)
+		return
+	}
+	auditHook.Store(&hook)
+}
+
+// auditIdent reports an identifier (table or column name) that contains SQL syntax
+func auditIdent(method, ident string) {
+	hook := auditHook.Load()
+	if hook == nil {
+		return
+	}
+	for _, marker := range []struct{ text, reason string }{
+		{"'", "quote in identifier"},
+		{";", "semicolon in identifier"},
+		{"--", "comment marker in identifier"},
+		{"/*", "comment marker in identifier"},
+	} {
+		if strings.Contains(ident, marker.text) {
+			(*hook)(AuditFinding{Method: method, Input: ident, Reason: marker.reason, Caller: callSite()})
+			return
+		}
+	}
+}
+
+// auditFragment reports a raw SQL fragment with inline string literals, statement
+// separators or comments. Placeholders and quoted identifiers are fine.
+func auditFragment(method, sql string) {
+	hook := auditHook.Load()
+	if hook == nil {
+		return
+	}
+
+	reported := map[string]bool{}
+	report := func(reason string) {
+		if !reported[reason] {
+			reported[reason] = true
+			(*hook)(AuditFinding{Method: method, Input: sql, Reason: reason, Caller: callSite()})
+		}
+	}
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'':
+			report("inline string literal")
+			i = skipQuoted(sql, i, c)
+		case c == '"':
+			i = skipQuoted(sql, i, c)
+		case c == ';':
+			report("semicolon")
+		case strings.HasPrefix(sql[i:], "--") || strings.HasPrefix(sql[i:], "/*"):
+			report("comment marker")
+			i++
+		}
+	}
+}
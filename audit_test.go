@@ -0,0 +1,59 @@
+package pgstring_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+// recordAudit enables audit mode for the test, collecting its findings
+func recordAudit(t *testing.T) *[]pgstring.AuditFinding {
+	var findings []pgstring.AuditFinding
+	pgstring.SetAuditHook(func(f pgstring.AuditFinding) { findings = append(findings, f) })
+	t.Cleanup(func() { pgstring.SetAuditHook(nil) })
+	return &findings
+}
+
+func TestAuditFlagsConcatenatedInput(t *testing.T) {
+	findings := recordAudit(t)
+	name := "x'; DROP TABLE users; --"
+	pgstring.Select("*").From("users").Where("name = '" + name + "'")
+
+	var reasons []string
+	for _, f := range *findings {
+		if f.Method != "Where" || !strings.HasPrefix(f.Caller, "pgstring_test.TestAuditFlagsConcatenatedInput (audit_test.go:") {
+			t.Errorf("unexpected finding %+v", f)
+		}
+		reasons = append(reasons, f.Reason)
+	}
+	if want := []string{"inline string literal", "semicolon", "comment marker"}; !reflect.DeepEqual(reasons, want) {
+		t.Errorf("got reasons %v, want %v", reasons, want)
+	}
+}
+
+func TestAuditFlagsIdentifiers(t *testing.T) {
+	findings := recordAudit(t)
+	pgstring.Select("*").From("users").WhereEqCI("email; --", "a")
+	if len(*findings) != 1 || (*findings)[0].Reason != "semicolon in identifier" {
+		t.Errorf("got %+v", *findings)
+	}
+}
+
+func TestAuditAllowsBoundArgs(t *testing.T) {
+	findings := recordAudit(t)
+	pgstring.Select(`"order"`).From("users").Where(`name = @name AND "select" = @s`, map[string]any{"name": "x'; --", "s": 1})
+	if len(*findings) != 0 {
+		t.Errorf("got findings %+v", *findings)
+	}
+}
+
+func TestAuditDisabled(t *testing.T) {
+	findings := recordAudit(t)
+	pgstring.SetAuditHook(nil)
+	pgstring.Select("*").From("users").Where("name = 'x'")
+	if len(*findings) != 0 {
+		t.Errorf("got findings %+v", *findings)
+	}
+}
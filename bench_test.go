@@ -0,0 +1,50 @@
+package pgstring_test
+
+import (
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+type benchUser struct {
+	ID     int    `db:"id,primarykey"`
+	Name   string `db:"name"`
+	Email  string `db:"email"`
+	Status string `db:"status"`
+	OrgID  int    `db:"org_id"`
+}
+
+func BenchmarkSelect(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = pgstring.Select(&benchUser{}).From("users").
+			Where("org_id = @org_id", map[string]any{"org_id": 7}).
+			Where("status = @status", map[string]any{"status": "active"}).
+			OrderBy("name").Limit(50).Build()
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	user := benchUser{ID: 1, Name: "Ada", Email: "ada@example.com", Status: "active", OrgID: 7}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = pgstring.InsertInto("users").Obj(user).Values(user).Returning("id").Build()
+	}
+}
+
+func BenchmarkInsertBulk(b *testing.B) {
+	users := make([]benchUser, 100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = pgstring.InsertInto("users").Obj(benchUser{}).ValuesBulk(users).Build()
+	}
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	user := benchUser{ID: 1, Name: "Ada", Email: "ada@example.com", Status: "active", OrgID: 7}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = pgstring.Update("users").Set(user).
+			Where("id = @id", map[string]any{"id": user.ID}).Returning("*").Build()
+	}
+}
@@ -0,0 +1,232 @@
+package pgstring
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BulkOption changes how ValuesBulk, UpdateBulk and DeleteBulk write their rows
+type BulkOption int
+
+const (
+	// BulkSkipConflicts adds ON CONFLICT DO NOTHING to ValuesBulk, so rows that conflict
+	// with existing ones are skipped instead of failing the statement. pgexec.InsertBulk
+	// reports which rows were inserted when the statement also has a RETURNING clause.
+	BulkSkipConflicts BulkOption = iota + 1
+	// BulkOrderByPK sorts the rows of UpdateBulk and DeleteBulk by primary key, so
+	// concurrent bulk writers lock shared rows in the same order instead of deadlocking
+	BulkOrderByPK
+)
+
+// withBulkOptions applies the options of a ValuesBulk call
+func (pg PgString) withBulkOptions(options []BulkOption) PgString {
+	for _, option := range options {
+		switch option {
+		case BulkSkipConflicts:
+			pg = pg.OnConflict("").DoNothing()
+		case BulkOrderByPK:
+			// Sorting would misalign the rows with RETURNING scans of the source slice
+			return pg.fail(errors.New("ValuesBulk: BulkOrderByPK only applies to UpdateBulk and DeleteBulk"))
+		}
+	}
+	return pg
+}
+
+// UpdateBulk creates an UPDATE assigning the writable columns of every element of objs,
+// a slice of structs, to the row with the same primarykey columns, in one statement:
+//
+//	UPDATE products SET name = v.name, price = v.price
+//	FROM (VALUES (@v_id_0::INTEGER, @v_name_0::TEXT, ...), ...) AS v(id, name, price)
+//	WHERE products.id = v.id
+//
+// BeforeUpdate hooks and validation run for each element. options are BulkOption values,
+// e.g. BulkOrderByPK.
+func UpdateBulk(table string, objs any, options ...BulkOption) PgString {
+	pg := Update(table)
+	models, keys, err := bulkModels(objs, options, beforeUpdate)
+	if err != nil {
+		return pg.fail(fmt.Errorf("UpdateBulk: %w", err))
+	}
+
+	info := structInfoOf(modelType(models[0]))
+	columns := slices.Clone(keys)
+	var setters []string
+	for _, column := range info.writable {
+		if !slices.Contains(keys, column) {
+			columns = append(columns, column)
+			setters = append(setters, quoteColumn(column)+" = v."+quoteColumn(column))
+		}
+	}
+	if len(setters) == 0 {
+		return pg.fail(errors.New("UpdateBulk: no columns to update besides the primary key"))
+	}
+
+	// The target is referred to by its alias, if it has one
+	fields := strings.Fields(table)
+	target := fields[len(fields)-1]
+	conditions := make([]string, len(keys))
+	for i, key := range keys {
+		conditions[i] = target + "." + quoteColumn(key) + " = v." + quoteColumn(key)
+	}
+
+	return pg.with(clause{kind: clauseSet, fields: setters}).
+		FromValues(models, "v", columns...).
+		with(clause{kind: clauseWhere, sql: strings.Join(conditions, " AND ")})
+}
+
+// DeleteBulk creates a DELETE of the rows with the primarykey columns of the elements of
+// objs, a slice of structs:
+//
+//	DELETE FROM products WHERE (id) IN (VALUES (@id_0), (@id_1))
+//
+// options are BulkOption values, e.g. BulkOrderByPK.
+func DeleteBulk(table string, objs any, options ...BulkOption) PgString {
+	pg := Delete().From(table)
+	models, keys, err := bulkModels(objs, options, nil)
+	if err != nil {
+		return pg.fail(fmt.Errorf("DeleteBulk: %w", err))
+	}
+
+	var b strings.Builder
+	b.WriteByte('(')
+	writeColumns(&b, keys)
+	b.WriteString(") IN (VALUES ")
+	for i, model := range models {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		bound, err := rowValues(reflect.Indirect(reflect.ValueOf(model)), keys, pg.nullPolicy)
+		if err != nil {
+			return pg.fail(fmt.Errorf("DeleteBulk: row %d: %w", i, err))
+		}
+		b.WriteByte('(')
+		for j, key := range keys {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			name := bulkArgName(key, i)
+			b.WriteString("@" + name)
+			pg = pg.withArg(name, bound[j])
+		}
+		b.WriteByte(')')
+	}
+	b.WriteByte(')')
+	return pg.with(clause{kind: clauseWhere, sql: b.String()})
+}
+
+// bulkModels returns the elements of objs, after running hook on each, and the primary key
+// columns of their struct type, sorting the elements by key for BulkOrderByPK
+func bulkModels(objs any, options []BulkOption, hook func(any) (any, error)) ([]any, []string, error) {
+	val := reflect.ValueOf(objs)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return nil, nil, errors.New("only slices of structs are supported")
+	}
+	if val.Len() == 0 {
+		return nil, nil, errors.New("no rows")
+	}
+
+	models := make([]any, val.Len())
+	for i := range models {
+		model := elemModel(val.Index(i))
+		elem, ok := structValue(model)
+		if !ok {
+			return nil, nil, errors.New("only slices of structs are supported")
+		}
+		if i > 0 && elem.Type() != modelType(models[0]) {
+			return nil, nil, fmt.Errorf("row %d is a %s, not a %s", i, elem.Type(), modelType(models[0]))
+		}
+		if hook != nil {
+			var err error
+			if model, err = hook(model); err != nil {
+				return nil, nil, fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		models[i] = model
+	}
+
+	keys, err := newModel("", modelType(models[0])).primaryKeys()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s has no primarykey field", modelType(models[0]))
+	}
+
+	for _, option := range options {
+		switch option {
+		case BulkOrderByPK:
+			info := structInfoOf(modelType(models[0]))
+			slices.SortStableFunc(models, func(a, b any) int {
+				return compareKeys(info, keys, reflect.Indirect(reflect.ValueOf(a)), reflect.Indirect(reflect.ValueOf(b)))
+			})
+		case BulkSkipConflicts:
+			return nil, nil, errors.New("BulkSkipConflicts only applies to ValuesBulk")
+		}
+	}
+	return models, keys, nil
+}
+
+// compareKeys orders two structs by their primary key columns
+func compareKeys(info *structInfo, keys []string, a, b reflect.Value) int {
+	for _, key := range keys {
+		for _, field := range info.fields {
+			if field.name != key {
+				continue
+			}
+			if c := compareValues(a.Field(field.index), b.Field(field.index)); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// compareValues orders two key values of the same type: numbers, strings, times and byte
+// arrays such as UUIDs in their natural order, other types by their printed form
+func compareValues(a, b reflect.Value) int {
+	if a.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			// NULL keys sort first
+			return cmp.Compare(boolInt(!a.IsNil()), boolInt(!b.IsNil()))
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+	switch {
+	case a.CanInt():
+		return cmp.Compare(a.Int(), b.Int())
+	case a.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint())
+	case a.CanFloat():
+		return cmp.Compare(a.Float(), b.Float())
+	case a.Kind() == reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case a.Type() == reflect.TypeFor[time.Time]():
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	case (a.Kind() == reflect.Array || a.Kind() == reflect.Slice) && a.Type().Elem().Kind() == reflect.Uint8:
+		return bytes.Compare(byteSlice(a), byteSlice(b))
+	}
+	return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// byteSlice returns the bytes of a byte array or slice value
+func byteSlice(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
@@ -0,0 +1,34 @@
+package pgstring_test
+
+import (
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestUpdateBulk(t *testing.T) {
+	rows := []product{{ID: 2, Name: "b", Price: 2}, {ID: 1, Name: "a", Price: 1}}
+	assertSQL(t, pgstring.UpdateBulk("products", rows, pgstring.BulkOrderByPK),
+		"UPDATE products SET name = v.name, price = v.price FROM (VALUES (@v_id_0::INTEGER, @v_name_0::TEXT, @v_price_0::DOUBLE PRECISION), "+
+			"(@v_id_1, @v_name_1, @v_price_1)) AS v(id, name, price) WHERE products.id = v.id",
+		map[string]any{"v_id_0": 1, "v_name_0": "a", "v_price_0": 1.0, "v_id_1": 2, "v_name_1": "b", "v_price_1": 2.0})
+}
+
+func TestDeleteBulk(t *testing.T) {
+	rows := []product{{ID: 2}, {ID: 1}}
+	assertSQL(t, pgstring.DeleteBulk("products", rows),
+		"DELETE FROM products WHERE (id) IN (VALUES (@id_0), (@id_1))",
+		map[string]any{"id_0": 2, "id_1": 1})
+}
+
+func TestBulkErrors(t *testing.T) {
+	assertErr(t, pgstring.UpdateBulk("products", []product{}), "no rows")
+	assertErr(t, pgstring.DeleteBulk("products", []product{{ID: 1}}, pgstring.BulkSkipConflicts), "only applies to ValuesBulk")
+	assertErr(t, pgstring.InsertInto("products").Obj(product{}).ValuesBulk([]product{{}}, pgstring.BulkOrderByPK), "only applies to UpdateBulk")
+}
+
+func TestValuesBulkSkipConflicts(t *testing.T) {
+	assertSQL(t, pgstring.InsertInto("products").Obj(product{}).ValuesBulk([]product{{ID: 1}}, pgstring.BulkSkipConflicts),
+		"INSERT INTO products (id, name, price) VALUES (@id_0, @name_0, @price_0) ON CONFLICT DO NOTHING",
+		map[string]any{"id_0": 1, "name_0": "", "price_0": 0.0})
+}
@@ -0,0 +1,55 @@
+package pgstring
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// callerComments enables caller comments for every query built
+var callerComments atomic.Bool
+
+// SetCallerComments makes Build prefix every query with a comment naming the Go call site
+// that built it, e.g. /* orders.List (orders.go:42) */, so pg_stat_activity and the
+// slow query log show where each query came from
+func SetCallerComments(enabled bool) {
+	callerComments.Store(enabled)
+}
+
+// WithCaller makes Build prefix this query with a comment naming its Go call site
+func (pg PgString) WithCaller() PgString {
+	pg.callerComment = true
+	return pg
+}
+
+// modulePath prefixes the functions of this module and its subpackages, which are skipped
+// when looking for the call site
+const modulePath = "github.com/oliverpaddock/pgstring"
+
+// callerComment returns a comment naming the first caller outside this module
+func callerComment() string {
+	site := callSite()
+	if site == "" {
+		return ""
+	}
+	return "/* " + strings.ReplaceAll(site, "*/", "* /") + " */ "
+}
+
+// callSite names the first caller outside this module, e.g. orders.List (orders.go:42)
+func callSite() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, modulePath+".") && !strings.HasPrefix(frame.Function, modulePath+"/") {
+			function := frame.Function[strings.LastIndexByte(frame.Function, '/')+1:]
+			return function + " (" + filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line) + ")"
+		}
+		if !more {
+			return ""
+		}
+	}
+}
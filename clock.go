@@ -0,0 +1,196 @@
+package pgstring
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	clock       atomic.Pointer[func() time.Time]
+	idGenerator atomic.Pointer[func() string]
+)
+
+// SetClock replaces the clock that fills createdat and updatedat fields and that ClockNow
+// returns, e.g. with FixedClock so tests get deterministic args; nil restores time.Now
+func SetClock(now func() time.Time) {
+	if now == nil {
+		clock.Store(nil)
+		return
+	}
+	clock.Store(&now)
+}
+
+// SetIDGenerator replaces the generator of the UUIDs that fill autouuid fields and that
+// NewID returns, e.g. with SequentialIDs in tests; nil restores random version 4 UUIDs
+func SetIDGenerator(newID func() string) {
+	if newID == nil {
+		idGenerator.Store(nil)
+		return
+	}
+	idGenerator.Store(&newID)
+}
+
+// ClockNow returns the current time from the clock set with SetClock, for hooks that set
+// timestamps themselves
+func ClockNow() time.Time {
+	if now := clock.Load(); now != nil {
+		return (*now)()
+	}
+	return time.Now()
+}
+
+// NewID returns a UUID from the generator set with SetIDGenerator, for hooks that assign
+// IDs themselves
+func NewID() string {
+	if newID := idGenerator.Load(); newID != nil {
+		return (*newID)()
+	}
+	return randomUUID()
+}
+
+// FixedClock returns a clock that always returns t
+func FixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
+
+// SequentialIDs returns a generator of the UUIDs 00000000-0000-0000-0000-000000000001,
+// 00000000-0000-0000-0000-000000000002 and so on
+func SequentialIDs() func() string {
+	var n atomic.Uint64
+	return func() string {
+		return fmt.Sprintf("00000000-0000-0000-0000-%012x", n.Add(1))
+	}
+}
+
+// randomUUID returns a random version 4 UUID
+func randomUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("pgstring: " + err.Error())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+func formatUUID(b [16]byte) string {
+	s := hex.EncodeToString(b[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// parseUUID parses the hex digits of a UUID, ignoring its dashes
+func parseUUID(s string) ([16]byte, error) {
+	var b [16]byte
+	digits := make([]byte, 0, 32)
+	for i := 0; i < len(s); i++ {
+		if s[i] != '-' {
+			digits = append(digits, s[i])
+		}
+	}
+	if len(digits) != 32 {
+		return b, fmt.Errorf("invalid UUID %q", s)
+	}
+	if _, err := hex.Decode(b[:], digits); err != nil {
+		return b, fmt.Errorf("invalid UUID %q", s)
+	}
+	return b, nil
+}
+
+// autoField is a tag option whose field is filled automatically
+type autoField string
+
+const (
+	autoNone    autoField = ""
+	autoCreated autoField = "createdat" // set to ClockNow on insert when zero
+	autoUpdated autoField = "updatedat" // set to ClockNow on every insert and update
+	autoUUID    autoField = "autouuid"  // set to NewID on insert when zero
+)
+
+// autoOf returns the auto option of a field's tag
+func autoOf(options []string) autoField {
+	for _, auto := range []autoField{autoCreated, autoUpdated, autoUUID} {
+		if hasOption(options, string(auto)) {
+			return auto
+		}
+	}
+	return autoNone
+}
+
+// fillAuto sets the zero createdat, updatedat and autouuid fields of obj before an insert,
+// or its updatedat fields before an update, returning the model to bind. Like hooks, it
+// modifies pointers in place and copies structs passed by value.
+func fillAuto(obj any, insert bool) (any, error) {
+	val, ok := structValue(obj)
+	if !ok {
+		return obj, nil
+	}
+	info := structInfoOf(val.Type())
+
+	var fill []fieldInfo
+	for _, field := range info.fields {
+		if field.auto == autoUpdated || insert && field.auto != autoNone && val.Field(field.index).IsZero() {
+			fill = append(fill, field)
+		}
+	}
+	if len(fill) == 0 {
+		return obj, nil
+	}
+	if !val.CanAddr() {
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		obj, val = ptr.Interface(), ptr.Elem()
+	}
+
+	var now time.Time
+	for _, field := range fill {
+		dest := val.Field(field.index)
+		switch field.auto {
+		case autoCreated, autoUpdated:
+			if now.IsZero() {
+				now = ClockNow()
+			}
+			if !setTime(dest, now) {
+				return obj, fmt.Errorf("%s: %s fields must be time.Time or *time.Time", field.name, field.auto)
+			}
+		case autoUUID:
+			if err := setUUID(dest, NewID()); err != nil {
+				return obj, fmt.Errorf("%s: %w", field.name, err)
+			}
+		}
+	}
+	return obj, nil
+}
+
+// setTime sets a time.Time or *time.Time field to t
+func setTime(dest reflect.Value, t time.Time) bool {
+	switch dest.Type() {
+	case reflect.TypeFor[time.Time]():
+		dest.Set(reflect.ValueOf(t))
+	case reflect.TypeFor[*time.Time]():
+		dest.Set(reflect.ValueOf(&t))
+	default:
+		return false
+	}
+	return true
+}
+
+// setUUID sets a string or [16]byte field, such as a uuid.UUID, to id
+func setUUID(dest reflect.Value, id string) error {
+	switch {
+	case dest.Kind() == reflect.String:
+		dest.SetString(id)
+	case dest.Kind() == reflect.Array && dest.Len() == 16 && dest.Type().Elem().Kind() == reflect.Uint8:
+		b, err := parseUUID(id)
+		if err != nil {
+			return err
+		}
+		reflect.Copy(dest, reflect.ValueOf(b[:]))
+	default:
+		return fmt.Errorf("autouuid fields must be strings or [16]byte, not %s", dest.Type())
+	}
+	return nil
+}
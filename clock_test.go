@@ -0,0 +1,33 @@
+package pgstring_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+type document struct {
+	ID        string    `db:"id,primarykey,autouuid"`
+	Title     string    `db:"title"`
+	CreatedAt time.Time `db:"created_at,createdat"`
+	UpdatedAt time.Time `db:"updated_at,updatedat"`
+}
+
+func TestAutoFields(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pgstring.SetClock(pgstring.FixedClock(now))
+	pgstring.SetIDGenerator(pgstring.SequentialIDs())
+	defer pgstring.SetClock(nil)
+	defer pgstring.SetIDGenerator(nil)
+
+	assertSQL(t, pgstring.InsertInto("documents").Obj(document{}).Values(document{Title: "a"}),
+		"INSERT INTO documents (id, title, created_at, updated_at) VALUES (@id, @title, @created_at, @updated_at)",
+		map[string]any{"id": "00000000-0000-0000-0000-000000000001", "title": "a", "created_at": now, "updated_at": now})
+
+	created := now.Add(-time.Hour)
+	assertSQL(t, pgstring.Update("documents").Set(document{ID: "x", Title: "b", CreatedAt: created}).
+		Where("id = @id", map[string]any{"id": "x"}),
+		"UPDATE documents SET id = @id, title = @title, created_at = @created_at, updated_at = @updated_at WHERE id = @id",
+		map[string]any{"id": "x", "title": "b", "created_at": created, "updated_at": now})
+}
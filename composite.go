@@ -0,0 +1,203 @@
+package pgstring
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CreateCompositeType creates a CREATE TYPE ... AS (...) statement declaring a Postgres
+// composite type with one attribute per mapped field of obj
+func CreateCompositeType(name string, obj any) PgString {
+	val, ok := structValue(obj)
+
+	// Only struct types are supported
+	if !ok {
+		return PgString{}.fail(fmt.Errorf("%v is not a struct", obj))
+	}
+
+	var b strings.Builder
+	b.WriteString("CREATE TYPE ")
+	b.WriteString(name)
+	b.WriteString(" AS (\n")
+	for i, field := range structInfoOf(val.Type()).fields {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		sqlType, _, _ := columnType(field.typ, field.options)
+		b.WriteString("    ")
+		b.WriteString(field.name)
+		b.WriteByte(' ')
+		b.WriteString(sqlType)
+	}
+	b.WriteString("\n)")
+
+	return ddl(b.String(), "")
+}
+
+// CompositeValue binds a struct as a single composite-type parameter
+type CompositeValue struct {
+	obj any
+}
+
+// Composite wraps obj so it binds as one parameter in the composite text format, e.g.
+//
+//	Where("home = @home::address", map[string]any{"home": pgstring.Composite(addr)})
+func Composite(obj any) CompositeValue {
+	return CompositeValue{obj: obj}
+}
+
+// Value implements driver.Valuer
+func (c CompositeValue) Value() (driver.Value, error) {
+	// Nil binds as NULL
+	if v := reflect.ValueOf(c.obj); !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, nil
+	}
+
+	val, ok := structValue(c.obj)
+	if !ok {
+		return nil, errNotStruct
+	}
+	return compositeLiteral(val)
+}
+
+// Row returns a ROW(...) expression with one placeholder per field of obj, named
+// name_field, and the args binding them. Cast the expression to the composite type:
+//
+//	expr, args, err := pgstring.Row("home", addr)
+//	q := pgstring.Update("users").Set(...).Where("home = "+expr+"::address", args)
+func Row(name string, obj any) (string, map[string]any, error) {
+	val, ok := structValue(obj)
+	if !ok {
+		return "", nil, errNotStruct
+	}
+
+	info := structInfoOf(val.Type())
+	args := make(map[string]any, len(info.fields))
+	placeholders := make([]string, len(info.fields))
+	for i, field := range info.fields {
+		value, err := field.bindValue(val, ZeroAsValue)
+		if err != nil {
+			return "", nil, err
+		}
+		key := name + "_" + field.name
+		placeholders[i] = "@" + key
+		args[key] = value
+	}
+
+	return "ROW(" + strings.Join(placeholders, ", ") + ")", args, nil
+}
+
+// compositeLiteral renders a struct in the composite text format: (a,b,"quoted c")
+func compositeLiteral(val reflect.Value) (string, error) {
+	var b strings.Builder
+	b.WriteByte('(')
+	for i, field := range structInfoOf(val.Type()).fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if enum := enumOf(field.typ); enum != nil {
+			if err := enum.validate(val.Field(field.index)); err != nil {
+				return "", fmt.Errorf("%s: %w", field.name, err)
+			}
+		}
+		text, null, err := compositeText(val.Field(field.index), field.jsonb)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", field.name, err)
+		}
+		// NULL attributes are left empty
+		if !null {
+			b.WriteString(quoteComposite(text))
+		}
+	}
+	b.WriteByte(')')
+	return b.String(), nil
+}
+
+// compositeText renders a single field value as text and reports whether it is NULL
+func compositeText(v reflect.Value, jsonb bool) (string, bool, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", true, nil
+		}
+		v = v.Elem()
+	}
+
+	if jsonb {
+		data, err := json.Marshal(v.Interface())
+		return string(data), false, err
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.Format(time.RFC3339Nano), false, nil
+	case driver.Valuer:
+		value, err := x.Value()
+		if err != nil || value == nil {
+			return "", value == nil, err
+		}
+		return compositeText(reflect.ValueOf(value), false)
+	case []byte:
+		return `\x` + fmt.Sprintf("%x", x), false, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), false, nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "t", false, nil
+		}
+		return "f", false, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), false, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), false, nil
+	case reflect.Struct:
+		text, err := compositeLiteral(v)
+		return text, false, err
+	case reflect.Slice, reflect.Array:
+		// Array literal with every element quoted
+		var b strings.Builder
+		b.WriteByte('{')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			text, null, err := compositeText(v.Index(i), false)
+			if err != nil {
+				return "", false, err
+			}
+			if null {
+				b.WriteString("NULL")
+				continue
+			}
+			b.WriteByte('"')
+			b.WriteString(escapeQuoted(text))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+		return b.String(), false, nil
+	}
+
+	return fmt.Sprint(v.Interface()), false, nil
+}
+
+// quoteComposite double-quotes an attribute when the composite text format requires it
+func quoteComposite(text string) string {
+	if text != "" && !strings.ContainsAny(text, "(),\"\\ \t\n\r") {
+		return text
+	}
+	return `"` + escapeQuoted(text) + `"`
+}
+
+// escapeQuoted escapes backslashes and double quotes inside a double-quoted value
+func escapeQuoted(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+}
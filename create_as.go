@@ -0,0 +1,56 @@
+package pgstring
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CreateTableAs creates a CREATE TABLE ... AS statement filling a new table from query,
+// e.g. for snapshot or reporting tables. query's named args are kept.
+func CreateTableAs(name string, query PgString, ifNotExists bool) PgString {
+	head := "CREATE TABLE " + name + " AS"
+	if ifNotExists {
+		head = "CREATE TABLE IF NOT EXISTS " + name + " AS"
+	}
+
+	query.clauses = slices.Insert(slices.Clone(query.clauses), 0, clause{kind: clauseRaw, sql: head, table: name, writes: true})
+	return query
+}
+
+// SelectInto adds an INTO clause to a SELECT query so its result creates a new table
+func (pg PgString) SelectInto(table string) PgString {
+	if len(pg.clauses) == 0 || (pg.clauses[0].kind != clauseSelect && pg.clauses[0].kind != clauseSelectDistinct) {
+		return pg.fail(fmt.Errorf("SelectInto %s requires a SELECT query", table))
+	}
+
+	pg.clauses = slices.Insert(slices.Clone(pg.clauses), 1, clause{kind: clauseInto, sql: table, table: table, writes: true})
+	return pg
+}
+
+// likeOptions are the properties CREATE TABLE ... (LIKE ...) can copy
+var likeOptions = []string{
+	"ALL", "COMMENTS", "COMPRESSION", "CONSTRAINTS", "DEFAULTS", "GENERATED",
+	"IDENTITY", "INDEXES", "STATISTICS", "STORAGE",
+}
+
+// CreateTableLike creates a table with the columns of sourceTable, e.g. for archive tables
+// and partition templates. including names the properties copied as well, such as
+// "DEFAULTS" or "INDEXES"; "EXCLUDING ..." items are passed through after validation.
+func CreateTableLike(newTable, sourceTable string, including ...string) PgString {
+	var b strings.Builder
+	b.WriteString("CREATE TABLE " + newTable + " (LIKE " + sourceTable)
+	for _, option := range including {
+		option = strings.ToUpper(strings.TrimSpace(option))
+		keyword := "INCLUDING"
+		if rest, ok := strings.CutPrefix(option, "EXCLUDING "); ok {
+			keyword, option = "EXCLUDING", strings.TrimSpace(rest)
+		}
+		if !slices.Contains(likeOptions, option) {
+			return PgString{}.fail(fmt.Errorf("unknown LIKE option %q", option))
+		}
+		b.WriteString(" " + keyword + " " + option)
+	}
+	b.WriteString(")")
+	return ddl(b.String(), newTable)
+}
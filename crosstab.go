@@ -0,0 +1,42 @@
+package pgstring
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// CrosstabSpec describes a pivot: one result row per RowKey with a column per category
+type CrosstabSpec struct {
+	RowKey     string // grouping column(s) of the result rows, e.g. "region"
+	Category   string // column whose values become result columns, e.g. "quarter"
+	Value      string // aggregate computed per category, e.g. "sum(amount)"
+	Categories []any  // category values, each becoming a column named after it
+}
+
+// Crosstab turns a SELECT query into a pivot using conditional aggregation, so the
+// tablefunc extension isn't needed. The query provides FROM, joins and WHERE conditions:
+//
+//	Select("*").From("sales").Where("year = @year", args).Crosstab(CrosstabSpec{
+//		RowKey: "region", Category: "quarter", Value: "sum(amount)", Categories: []any{"Q1", "Q2"},
+//	})
+//
+// renders SELECT region, sum(amount) FILTER (WHERE quarter = @crosstab_0) AS "Q1", ...
+// FROM sales WHERE year = @year GROUP BY region.
+func (pg PgString) Crosstab(spec CrosstabSpec) PgString {
+	if spec.RowKey == "" || spec.Category == "" || spec.Value == "" {
+		return pg.fail(errors.New("crosstab requires a row key, category and value"))
+	}
+	if len(spec.Categories) == 0 {
+		return pg.fail(errors.New("crosstab requires at least one category"))
+	}
+
+	items := []any{spec.RowKey}
+	for i, category := range spec.Categories {
+		arg := Arg("crosstab_"+strconv.Itoa(i), category)
+		column := spec.Value + " FILTER (WHERE " + spec.Category + " = " + arg.sql + ") AS " + quoteIdent(fmt.Sprint(category))
+		items = append(items, Expr{sql: column, args: arg.args})
+	}
+
+	return pg.ReplaceSelect(join(items, ", ", "", "")).GroupBy(spec.RowKey)
+}
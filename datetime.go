@@ -0,0 +1,55 @@
+package pgstring
+
+import "time"
+
+// Date/time expression helpers return an Expr, like the other expression helpers, so they
+// compose with Coalesce, arithmetic and SelectExpr; String gives their SQL for WHERE
+// conditions and GROUP BY clauses. Items are column names, numbers or other Exprs:
+//
+//	day := DateTrunc("day", "created_at")
+//	SelectExpr(day.As("day"), Count("*")).From("orders").GroupBy(day.String())
+
+// Now returns the transaction start time, now()
+func Now() Expr {
+	return Expr{sql: "now()"}
+}
+
+// CurrentDate returns CURRENT_DATE
+func CurrentDate() Expr {
+	return Expr{sql: "CURRENT_DATE"}
+}
+
+// DateTrunc truncates item to unit (e.g. "hour", "day", "week", "month")
+func DateTrunc(unit string, item any) Expr {
+	return call("date_trunc", []any{quoteLiteral(unit), item})
+}
+
+// AgeOf returns the interval between now and item, age(item)
+func AgeOf(item any) Expr {
+	return call("age", []any{item})
+}
+
+// Extract returns a field of item (e.g. "year", "dow", "epoch") as a number
+func Extract(part string, item any) Expr {
+	e := exprOf(item)
+	e.sql = "EXTRACT(" + quoteLiteral(part) + " FROM " + e.sql + ")"
+	e.binary = false
+	return e
+}
+
+// WhereDateRange filters column to the range from an optional lower and upper bound, both
+// inclusive: BETWEEN when both are set, >= or <= when only one is, and nothing when neither
+// is. Bounds bind as @column_start and @column_end, like Between.
+func (pg PgString) WhereDateRange(column string, from, to *time.Time) PgString {
+	name := argName(column)
+	switch {
+	case from != nil && to != nil:
+		return pg.Between(column, *from, *to)
+	case from != nil:
+		return pg.with(clause{kind: clauseWhere, sql: column + " >= @" + name + "_start"}).withArg(name+"_start", *from)
+	case to != nil:
+		return pg.with(clause{kind: clauseWhere, sql: column + " <= @" + name + "_end"}).withArg(name+"_end", *to)
+	default:
+		return pg
+	}
+}
@@ -0,0 +1,42 @@
+package pgstring_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestDateTimeHelpers(t *testing.T) {
+	day := pgstring.DateTrunc("day", "created_at")
+	assertSQL(t, pgstring.SelectExpr(day.As("day"), pgstring.Count("*")).From("orders").
+		Where("created_at > "+pgstring.Now().Sub("interval '7 days'").String()).
+		GroupBy(day.String()),
+		"SELECT date_trunc('day', created_at) AS day, COUNT(*) FROM orders WHERE created_at > now() - interval '7 days' GROUP BY date_trunc('day', created_at)",
+		nil)
+}
+
+func TestDateTimeHelpersCompose(t *testing.T) {
+	assertSQL(t, pgstring.SelectExpr(
+		pgstring.Extract("year", pgstring.Coalesce("shipped_at", pgstring.CurrentDate())).As("year"),
+		pgstring.AgeOf(pgstring.Arg("since", "2024-01-01")),
+	).From("orders"),
+		"SELECT EXTRACT('year' FROM COALESCE(shipped_at, CURRENT_DATE)) AS year, age(@since) FROM orders",
+		map[string]any{"since": "2024-01-01"})
+}
+
+func TestWhereDateRange(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	assertSQL(t, pgstring.Select("*").From("orders o").WhereDateRange("o.created_at", &from, &to),
+		"SELECT * FROM orders o WHERE o.created_at BETWEEN @o_created_at_start AND @o_created_at_end",
+		map[string]any{"o_created_at_start": from, "o_created_at_end": to})
+	assertSQL(t, pgstring.Select("*").From("orders o").WhereDateRange("o.created_at", &from, nil),
+		"SELECT * FROM orders o WHERE o.created_at >= @o_created_at_start",
+		map[string]any{"o_created_at_start": from})
+	assertSQL(t, pgstring.Select("*").From("orders o").WhereDateRange("o.created_at", nil, &to),
+		"SELECT * FROM orders o WHERE o.created_at <= @o_created_at_end",
+		map[string]any{"o_created_at_end": to})
+	assertSQL(t, pgstring.Select("*").From("orders").WhereDateRange("created_at", nil, nil),
+		"SELECT * FROM orders", nil)
+}
@@ -0,0 +1,96 @@
+package pgstring
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DebugString renders the query with its named args substituted as literals, for logs and
+// slow query reports. It is meant to be read, not executed; use Build to run the query.
+// Sensitive args are shown as '[redacted]', even when the query inlines its args.
+func (pg PgString) DebugString() string {
+	sql, args, err := pg.build(false)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return inlineArgs(sql, pg.redact(args, redactedLiteral{}))
+}
+
+// inlineArgs replaces the placeholders of sql that have an arg with the arg's literal
+func inlineArgs(sql string, args map[string]any) string {
+	var b strings.Builder
+	last := 0
+	scanPlaceholders(sql, func(start, end int) {
+		value, ok := args[sql[start+1:end]]
+		if !ok {
+			return
+		}
+		b.WriteString(sql[last:start])
+		b.WriteString(debugLiteral(value))
+		last = end
+	})
+	b.WriteString(sql[last:])
+	return b.String()
+}
+
+// debugLiteral renders value as a SQL literal
+func debugLiteral(value any) string {
+	// Driver values like pgtype.Text render as the value they bind
+	if valuer, ok := value.(driver.Valuer); ok && !isNilPointer(value) {
+		if v, err := valuer.Value(); err == nil {
+			if _, again := v.(driver.Valuer); !again {
+				return debugLiteral(v)
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteLiteral(v)
+	case []byte:
+		return `'\x` + hex.EncodeToString(v) + "'"
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return quoteLiteral(v.Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		return quoteLiteral(v.String())
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return "NULL"
+		}
+		return debugLiteral(val.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return enclosedNumber(fmt.Sprint(value))
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return "NULL"
+		}
+		items := make([]string, val.Len())
+		for i := range items {
+			items[i] = debugLiteral(val.Index(i).Interface())
+		}
+		return "ARRAY[" + strings.Join(items, ", ") + "]"
+	default:
+		return quoteLiteral(fmt.Sprint(value))
+	}
+}
+
+// isNilPointer reports whether value is a nil pointer, whose methods may not be callable
+func isNilPointer(value any) bool {
+	val := reflect.ValueOf(value)
+	return val.Kind() == reflect.Ptr && val.IsNil()
+}
@@ -0,0 +1,30 @@
+package pgstring_test
+
+import (
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestDebugString(t *testing.T) {
+	q := pgstring.Select("*").From("users").Where("name = @name AND age > @age", map[string]any{"name": "o'brien", "age": 30})
+	if got, want := q.DebugString(), "SELECT * FROM users WHERE name = 'o''brien' AND age > 30"; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestDebugStringSkipsQuotedAndComments(t *testing.T) {
+	q := pgstring.Select("'@id', \"@id\", $$@id$$, $tag$ @id $tag$ /* @id */").From("t").
+		Where("tags @> @tags AND id = @id -- @id\n", map[string]any{"id": 1, "tags": []string{"a"}})
+	want := "SELECT '@id', \"@id\", $$@id$$, $tag$ @id $tag$ /* @id */ FROM t WHERE tags @> ARRAY['a'] AND id = 1 -- @id\n"
+	if got := q.DebugString(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestDebugStringError(t *testing.T) {
+	q := pgstring.InsertInto("products").Obj(product{}).ValuesBulk([]product{})
+	if got := q.DebugString(); got != "Error: ValuesBulk: no rows" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,123 @@
+package pgstring
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// FromValues adds a FROM clause selecting from a VALUES list of bound rows, for joining
+// application data against tables without a temporary table:
+//
+//	FROM (VALUES (@v_id_0::INTEGER, @v_qty_0::INTEGER), (@v_id_1, @v_qty_1)) AS v(id, qty)
+//
+// rows is a slice of structs, whose mapped columns are used unless columns are given,
+// or a slice of []any rows with one value per column. Placeholders in the first row are
+// cast to the column's type when it can be derived from the Go type, since Postgres would
+// otherwise treat the parameters as text.
+func (pg PgString) FromValues(rows any, alias string, columns ...string) PgString {
+	val := reflect.ValueOf(rows)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return pg.fail(errors.New("FromValues requires a slice of rows"))
+	}
+	if val.Len() == 0 {
+		return pg.fail(fmt.Errorf("FromValues %s has no rows", alias))
+	}
+
+	values := make([][]any, val.Len())
+	types := make([]reflect.Type, len(columns))
+	for i := range values {
+		row := val.Index(i)
+		if row.Kind() == reflect.Interface {
+			row = row.Elem()
+		}
+
+		if elem, ok := structValue(row.Interface()); ok {
+			info := structInfoOf(elem.Type())
+			if len(columns) == 0 {
+				columns = info.names
+				types = make([]reflect.Type, len(columns))
+			}
+			bound, err := rowValues(elem, columns, pg.nullPolicy)
+			if err != nil {
+				return pg.fail(fmt.Errorf("row %d: %w", i, err))
+			}
+			values[i] = bound
+			for j, column := range columns {
+				for _, field := range info.fields {
+					if field.name == column && types[j] == nil {
+						types[j] = field.typ
+					}
+				}
+			}
+			continue
+		}
+
+		cells, ok := row.Interface().([]any)
+		if !ok {
+			return pg.fail(fmt.Errorf("row %d: rows must be structs or []any, got %s", i, row.Type()))
+		}
+		if len(cells) != len(columns) {
+			return pg.fail(fmt.Errorf("row %d has %d values for %d columns", i, len(cells), len(columns)))
+		}
+		values[i] = cells
+		for j, cell := range cells {
+			if cell != nil && types[j] == nil {
+				types[j] = reflect.TypeOf(cell)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("(VALUES ")
+	for i, row := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j, column := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			name := bulkArgName(alias+"_"+column, i)
+			b.WriteString("@" + name)
+			if i == 0 {
+				if cast := valuesCast(types[j]); cast != "" {
+					b.WriteString("::" + cast)
+				}
+			}
+			pg = pg.withArg(name, row[j])
+		}
+		b.WriteByte(')')
+	}
+	b.WriteString(") AS " + alias + "(" + strings.Join(columns, ", ") + ")")
+
+	return pg.with(clause{kind: clauseFrom, sql: b.String()})
+}
+
+// valuesCast returns the type a VALUES column of Go type t is cast to, or "" when
+// it can't be derived reliably
+func valuesCast(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+	default:
+		if t != reflect.TypeOf(time.Time{}) {
+			return ""
+		}
+	}
+
+	sqlType, _, _ := columnType(t, nil)
+	return sqlType
+}
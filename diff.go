@@ -0,0 +1,64 @@
+package pgstring
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+)
+
+// SetDiff adds a SET clause assigning only the columns whose values differ between two
+// structs of the same type, to spare the WAL and update triggers. The fields of newObj are
+// bound as named args, as with Set. When nothing changed the statement is marked Unchanged
+// and pgexec skips it.
+func (pg PgString) SetDiff(oldObj, newObj any) PgString {
+	oldVal, ok := structValue(oldObj)
+	newVal, ok2 := structValue(newObj)
+	if !ok || !ok2 {
+		return pg.fail(errNotStruct)
+	}
+	if oldVal.Type() != newVal.Type() {
+		return pg.fail(errors.New("SetDiff needs two values of the same struct type"))
+	}
+	newObj, err := beforeUpdate(newObj)
+	if err != nil {
+		return pg.fail(err)
+	}
+	newVal, _ = structValue(newObj)
+
+	namedArgs, err := extractNamedArgs(newObj, pg.nullPolicy)
+	if err != nil {
+		return pg.fail(err)
+	}
+	pg.args = append(slices.Clip(pg.args), namedArgs...)
+
+	info := structInfoOf(newVal.Type())
+	var setters, touched []string
+	for _, field := range info.fields {
+		if !slices.Contains(info.writable, field.name) {
+			continue
+		}
+		// updatedat fields always differ, so they are only assigned along with a change
+		if field.auto == autoUpdated {
+			touched = append(touched, quoteColumn(field.name)+" = @"+field.name)
+			continue
+		}
+		if !reflect.DeepEqual(oldVal.Field(field.index).Interface(), newVal.Field(field.index).Interface()) {
+			setters = append(setters, quoteColumn(field.name)+" = @"+field.name)
+		}
+	}
+	if len(setters) == 0 {
+		pg.unchanged = true
+		return pg
+	}
+	setters = append(setters, touched...)
+	return pg.with(clause{kind: clauseSet, fields: setters})
+}
+
+// Unchanged reports whether SetDiff found nothing to update, so the statement needn't run
+func (pg PgString) Unchanged() bool {
+	if !pg.unchanged {
+		return false
+	}
+	// Assignments added after SetDiff still need the UPDATE
+	return !slices.ContainsFunc(pg.clauses, func(c clause) bool { return c.kind == clauseSet })
+}
@@ -0,0 +1,97 @@
+package pgstring
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// enumInfo describes a registered enum type
+type enumInfo struct {
+	values   []string
+	typeName string // set for enums declared with CREATE TYPE ... AS ENUM
+}
+
+var (
+	// enums maps registered Go types to their enumInfo
+	enums sync.Map
+	// enumCount lets binding skip the registry lookup until an enum is registered
+	enumCount atomic.Int32
+)
+
+// RegisterEnum registers the allowed values of a string-based type. CreateTable declares
+// columns of the type as TEXT with a CHECK constraint, and binding a value outside the set
+// records an error on the query.
+func RegisterEnum[T ~string](values ...T) {
+	registerEnum(reflect.TypeFor[T](), "", values)
+}
+
+// RegisterEnumType is like RegisterEnum but declares the type as a Postgres enum named
+// typeName. CreateTable creates the type if it doesn't already exist.
+func RegisterEnumType[T ~string](typeName string, values ...T) {
+	registerEnum(reflect.TypeFor[T](), typeName, values)
+}
+
+func registerEnum[T ~string](typ reflect.Type, typeName string, values []T) {
+	info := &enumInfo{typeName: typeName, values: make([]string, len(values))}
+	for i, v := range values {
+		info.values[i] = string(v)
+	}
+	if _, loaded := enums.Swap(typ, info); !loaded {
+		enumCount.Add(1)
+	}
+}
+
+// enumOf returns the enum registered for typ or a pointer or slice of it
+func enumOf(typ reflect.Type) *enumInfo {
+	if enumCount.Load() == 0 {
+		return nil
+	}
+	if typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if info, ok := enums.Load(typ); ok {
+		return info.(*enumInfo)
+	}
+	return nil
+}
+
+// validate checks that a field value (or every element of a slice) is one of the enum's values
+func (e *enumInfo) validate(field reflect.Value) error {
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	case reflect.Slice:
+		for i := 0; i < field.Len(); i++ {
+			if err := e.validate(field.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !slices.Contains(e.values, field.String()) {
+		return fmt.Errorf("invalid value %q for enum %s", field.String(), field.Type())
+	}
+	return nil
+}
+
+// valueList renders the enum's values as a list of SQL string literals
+func (e *enumInfo) valueList() string {
+	quoted := make([]string, len(e.values))
+	for i, v := range e.values {
+		quoted[i] = quoteLiteral(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// createType renders an idempotent CREATE TYPE ... AS ENUM statement
+func (e *enumInfo) createType() string {
+	return fmt.Sprintf("DO $$ BEGIN CREATE TYPE %s AS ENUM (%s); EXCEPTION WHEN duplicate_object THEN NULL; END $$",
+		e.typeName, e.valueList())
+}
@@ -0,0 +1,119 @@
+package pgstring
+
+import "time"
+
+// ExecOptions is execution metadata attached to a statement and honored by pgexec
+type ExecOptions struct {
+	// Retryable statements are retried with backoff on serialization failures and deadlocks
+	Retryable bool
+	// LockTimeout bounds how long the statement waits for locks, applied with SET LOCAL
+	LockTimeout time.Duration
+	// IdempotencyKey identifies the operation: pgexec records it in IdempotencyTable in the
+	// statement's transaction and skips the statement when the key was already applied
+	IdempotencyKey string
+	// SlowThreshold is the duration past which OnSlow is called with the statement's plan
+	SlowThreshold time.Duration
+	// OnSlow reports executions that took longer than SlowThreshold
+	OnSlow func(SlowQuery)
+	// Timeout bounds the execution with a context deadline; for Query it covers reading the rows
+	Timeout time.Duration
+	// MaxRows makes Query fail with pgexec.ErrTooManyRows once more rows than this are read
+	MaxRows int
+	// ReadOnly asserts the statement doesn't write: pgexec rejects inserts, updates, deletes
+	// and DDL, and Router sends it to the replica even when it is raw SQL
+	ReadOnly bool
+	// Pool names the Router pool the statement runs on, e.g. "reporting"
+	Pool string
+}
+
+// SlowQuery describes an execution that exceeded its SlowThreshold
+type SlowQuery struct {
+	Duration time.Duration
+	// Query is the DebugString of the statement
+	Query string
+	// Plan is the EXPLAIN output of the statement, empty when PlanErr is set
+	Plan    string
+	PlanErr error
+}
+
+// Retryable marks the statement as safe to retry on serialization failures and deadlocks
+func (pg PgString) Retryable() PgString {
+	pg.exec.Retryable = true
+	return pg
+}
+
+// LockTimeout sets the lock_timeout for the statement, so DDL or hot-row updates fail fast
+// instead of queueing behind long-held locks
+func (pg PgString) LockTimeout(d time.Duration) PgString {
+	pg.exec.LockTimeout = d
+	return pg
+}
+
+// IdempotencyKey attaches a key identifying the operation, e.g. a client request ID, so it
+// is applied at most once: pgexec records the key in IdempotencyTable in the same transaction
+// as the statement and skips statements whose key is already there. Keyed statements are
+// retried like Retryable ones.
+func (pg PgString) IdempotencyKey(key string) PgString {
+	pg.exec.IdempotencyKey = key
+	return pg
+}
+
+// IdempotencyTable records the keys of applied IdempotencyKey statements
+const IdempotencyTable = "pgstring_idempotency_keys"
+
+// CreateIdempotencyTable creates IdempotencyTable if it doesn't exist
+func CreateIdempotencyTable() PgString {
+	return RawSQL("CREATE TABLE IF NOT EXISTS " + IdempotencyTable +
+		" (key TEXT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT now())")
+}
+
+// SlowQuery makes pgexec call report with the statement's DebugString and EXPLAIN plan when
+// an execution takes longer than threshold, so slow queries report themselves
+func (pg PgString) SlowQuery(threshold time.Duration, report func(SlowQuery)) PgString {
+	pg.exec.SlowThreshold = threshold
+	pg.exec.OnSlow = report
+	return pg
+}
+
+// QueryTimeout bounds the statement's execution, so a slow query fails instead of holding
+// a connection indefinitely
+func (pg PgString) QueryTimeout(d time.Duration) PgString {
+	pg.exec.Timeout = d
+	return pg
+}
+
+// MaxRows caps the rows a query may return; reading more fails the query
+func (pg PgString) MaxRows(n int) PgString {
+	pg.exec.MaxRows = n
+	return pg
+}
+
+// ReadOnly marks the statement as read-only, so it is routed to a replica and fails
+// rather than runs if it would write
+func (pg PgString) ReadOnly() PgString {
+	pg.exec.ReadOnly = true
+	return pg
+}
+
+// Pool sets the name of the Router pool the statement runs on
+func (pg PgString) Pool(name string) PgString {
+	pg.exec.Pool = name
+	return pg
+}
+
+// WithExecOptions replaces the statement's execution metadata with opts, so a policy
+// shared by several queries can be defined once
+func (pg PgString) WithExecOptions(opts ExecOptions) PgString {
+	pg.exec = opts
+	return pg
+}
+
+// ExecOptions returns the statement's execution metadata
+func (pg PgString) ExecOptions() ExecOptions {
+	return pg.exec
+}
+
+// ShouldRetry reports whether the statement may be retried after a transient failure
+func (o ExecOptions) ShouldRetry() bool {
+	return o.Retryable || o.IdempotencyKey != ""
+}
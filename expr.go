@@ -0,0 +1,177 @@
+package pgstring
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Expr is a SQL expression together with the named args it binds. Expression items are
+// strings, used as SQL (usually a column name), numeric literals or other Exprs.
+type Expr struct {
+	sql    string
+	args   []namedArg
+	err    error
+	binary bool // parenthesized when used as an operand
+}
+
+// Col refers to a column in an expression. Reserved words like order are quoted.
+func Col(name string) Expr {
+	return Expr{sql: quoteColumn(name)}
+}
+
+// Field refers to a column in a SelectFields projection; it is the same as Col
+func Field(name string) Expr {
+	return Col(name)
+}
+
+// Add returns e + other
+func (e Expr) Add(other any) Expr {
+	return e.operator("+", other)
+}
+
+// Sub returns e - other
+func (e Expr) Sub(other any) Expr {
+	return e.operator("-", other)
+}
+
+// Mul returns e * other
+func (e Expr) Mul(other any) Expr {
+	return e.operator("*", other)
+}
+
+// Div returns e / other. Integer operands use integer division.
+func (e Expr) Div(other any) Expr {
+	return e.operator("/", other)
+}
+
+// operator combines e and other with a binary operator
+func (e Expr) operator(op string, other any) Expr {
+	result := join([]any{e.operand(), exprOf(other).operand()}, " "+op+" ", "", "")
+	result.binary = true
+	return result
+}
+
+// operand parenthesizes a binary expression so it keeps its precedence inside another one
+func (e Expr) operand() Expr {
+	if e.binary {
+		e.sql = "(" + e.sql + ")"
+		e.binary = false
+	}
+	return e
+}
+
+// Arg binds value as the named arg @name. Without a value it only refers to @name, for args
+// bound elsewhere, e.g. by a later Where.
+func Arg(name string, value ...any) Expr {
+	switch len(value) {
+	case 0:
+		return Expr{sql: "@" + name}
+	case 1:
+		return Expr{sql: "@" + name, args: []namedArg{{name: name, value: value[0]}}}
+	default:
+		return Expr{err: fmt.Errorf("Arg %s takes one value, got %d", name, len(value))}
+	}
+}
+
+// String returns the expression's SQL
+func (e Expr) String() string {
+	return e.sql
+}
+
+// As aliases the expression in a SELECT list
+func (e Expr) As(alias string) Expr {
+	e.sql += " AS " + alias
+	e.binary = false
+	return e
+}
+
+// Coalesce returns the first non-NULL item, COALESCE(a, b, ...)
+func Coalesce(items ...any) Expr {
+	return call("COALESCE", items)
+}
+
+// NullIf returns NULL when a equals b and a otherwise, NULLIF(a, b)
+func NullIf(a, b any) Expr {
+	return call("NULLIF", []any{a, b})
+}
+
+// Greatest returns the largest item, GREATEST(a, b, ...)
+func Greatest(items ...any) Expr {
+	return call("GREATEST", items)
+}
+
+// Least returns the smallest item, LEAST(a, b, ...)
+func Least(items ...any) Expr {
+	return call("LEAST", items)
+}
+
+// exprOf converts an expression item to an Expr
+func exprOf(item any) Expr {
+	switch v := item.(type) {
+	case Expr:
+		return v
+	case string:
+		return Expr{sql: v}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		// Numbers are safe to render as literals
+		return Expr{sql: fmt.Sprint(v)}
+	default:
+		return Expr{err: fmt.Errorf("unsupported expression %T, use a string, number or Expr", item)}
+	}
+}
+
+// call renders a function call of items, merging their args
+func call(function string, items []any) Expr {
+	return join(items, ", ", function+"(", ")")
+}
+
+// join renders items separated by sep between prefix and suffix, merging their args
+func join(items []any, sep, prefix, suffix string) Expr {
+	var result Expr
+	parts := make([]string, len(items))
+	for i, item := range items {
+		e := exprOf(item)
+		if e.err != nil && result.err == nil {
+			result.err = e.err
+		}
+		parts[i] = e.sql
+		result.args = append(result.args, e.args...)
+	}
+	result.sql = prefix + strings.Join(parts, sep) + suffix
+	return result
+}
+
+// withExpr merges an expression's args into the query, recording its error
+func (pg PgString) withExpr(e Expr) PgString {
+	if e.err != nil {
+		return pg.fail(e.err)
+	}
+	pg.args = append(slices.Clip(pg.args), e.args...)
+	return pg
+}
+
+// SelectExpr creates a SELECT query from a list of columns and expressions:
+//
+//	SelectExpr("id", Coalesce("nickname", "name").As("display_name"))
+func SelectExpr(items ...any) PgString {
+	e := join(items, ", ", "", "")
+	return PgString{}.with(clause{kind: clauseSelect, sql: e.sql}).withExpr(e)
+}
+
+// SelectFields creates a SELECT query from typed column expressions, binding their args:
+//
+//	SelectFields(Field("id"), Field("price").Mul(Arg("rate", rate)).As("converted"), Count("*").As("n"))
+func SelectFields(fields ...Expr) PgString {
+	items := make([]any, len(fields))
+	for i, field := range fields {
+		items[i] = field
+	}
+	return SelectExpr(items...)
+}
+
+// SetExpr adds a column = expression assignment to the SET clause of an UPDATE query
+func (pg PgString) SetExpr(column string, value any) PgString {
+	e := exprOf(value)
+	return pg.with(clause{kind: clauseSet, sql: quoteColumn(column) + " = " + e.sql}).withExpr(e)
+}
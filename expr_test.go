@@ -0,0 +1,22 @@
+package pgstring_test
+
+import (
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestSelectFields(t *testing.T) {
+	assertSQL(t, pgstring.SelectFields(
+		pgstring.Field("id"),
+		pgstring.Field("price").Mul(pgstring.Arg("rate", 0.92)).As("converted"),
+		pgstring.Count("*").As("n"),
+	).From("products").GroupBy("id"),
+		"SELECT id, price * @rate AS converted, COUNT(*) AS n FROM products GROUP BY id",
+		map[string]any{"rate": 0.92})
+}
+
+func TestFieldQuotesReservedWords(t *testing.T) {
+	assertSQL(t, pgstring.SelectFields(pgstring.Field("order")).From("items"),
+		`SELECT "order" FROM items`, nil)
+}
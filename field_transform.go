@@ -0,0 +1,156 @@
+package pgstring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Transformer converts the values of fields tagged db:",transform=name" (or db:",encrypt",
+// short for transform=encrypt) at the builder boundary: Bind when they are bound as args
+// and Scan when GenerateFieldPointers, Field.Pointer or pgscan scan them back
+type Transformer struct {
+	// Bind converts a field's value to the value bound for its column
+	Bind func(value any) (any, error)
+	// Scan converts a non-NULL column value back to a value assignable to the field
+	Scan func(src any) (any, error)
+	// Type is the column type CreateTable uses for the field, BYTEA when empty
+	Type string
+}
+
+// transformers maps transformer names to their Transformer
+var transformers sync.Map
+
+// RegisterTransformer registers t under name for fields tagged db:",transform=name".
+// Register the "encrypt" transformer to use db:",encrypt":
+//
+//	t, err := pgstring.AESGCM(key)
+//	pgstring.RegisterTransformer("encrypt", t)
+func RegisterTransformer(name string, t Transformer) {
+	transformers.Store(name, t)
+}
+
+// transformName returns the name of a field's transformer, if any
+func transformName(options []string) string {
+	if name, ok := optionValue(options, "transform"); ok {
+		return name
+	}
+	if hasOption(options, "encrypt") {
+		return "encrypt"
+	}
+	return ""
+}
+
+// transformerOf returns the registered transformer called name
+func transformerOf(name string) (Transformer, error) {
+	if t, ok := transformers.Load(name); ok {
+		return t.(Transformer), nil
+	}
+	return Transformer{}, fmt.Errorf("no transformer %q registered, call RegisterTransformer", name)
+}
+
+// bindTransformed returns the value bound for a transformed field; nil pointers bind NULL
+func bindTransformed(name string, field reflect.Value) (any, error) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil, nil
+		}
+		field = field.Elem()
+	}
+	t, err := transformerOf(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Bind(field.Interface())
+}
+
+// transformScanner scans a transformed column into the field it points to
+type transformScanner struct {
+	dest any
+	name string
+}
+
+func (s transformScanner) Scan(src any) error {
+	dest := reflect.ValueOf(s.dest).Elem()
+	if src == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+
+	t, err := transformerOf(s.name)
+	if err != nil {
+		return err
+	}
+	value, err := t.Scan(src)
+	if err != nil {
+		return err
+	}
+
+	target := dest
+	if dest.Kind() == reflect.Ptr {
+		target = reflect.New(dest.Type().Elem()).Elem()
+	}
+	val := reflect.ValueOf(value)
+	if !val.IsValid() || !val.Type().ConvertibleTo(target.Type()) {
+		return fmt.Errorf("transformer %q returned %T for a %s field", s.name, value, target.Type())
+	}
+	target.Set(val.Convert(target.Type()))
+	if dest.Kind() == reflect.Ptr {
+		dest.Set(target.Addr())
+	}
+	return nil
+}
+
+// AESGCM returns an "encrypt" Transformer for string and []byte fields, sealing values with
+// AES-GCM under key (16, 24 or 32 bytes) into BYTEA columns, a random nonce first. Each
+// bind encrypts differently, so encrypted columns can't be matched in WHERE conditions.
+func AESGCM(key []byte) (Transformer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return Transformer{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return Transformer{}, err
+	}
+
+	return Transformer{
+		Bind: func(value any) (any, error) {
+			var plain []byte
+			switch v := value.(type) {
+			case string:
+				plain = []byte(v)
+			case []byte:
+				plain = v
+			default:
+				return nil, fmt.Errorf("can't encrypt %T, only strings and []byte", value)
+			}
+			nonce := make([]byte, aead.NonceSize())
+			if _, err := rand.Read(nonce); err != nil {
+				return nil, err
+			}
+			return aead.Seal(nonce, nonce, plain, nil), nil
+		},
+		Scan: func(src any) (any, error) {
+			var sealed []byte
+			switch v := src.(type) {
+			case []byte:
+				sealed = v
+			case string:
+				sealed = []byte(v)
+			default:
+				return nil, fmt.Errorf("can't decrypt %T", src)
+			}
+			if len(sealed) < aead.NonceSize() {
+				return nil, errors.New("encrypted value is too short")
+			}
+			nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+			return aead.Open(nil, nonce, sealed, nil)
+		},
+		Type: "BYTEA",
+	}, nil
+}
@@ -0,0 +1,310 @@
+package pgstring
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// fieldInfo describes an exported struct field mapped to a column
+type fieldInfo struct {
+	name        string
+	index       int
+	null        bool   // db:",null" binds the zero value as NULL
+	notNullZero bool   // db:",notnullzero" always binds the zero value as is
+	jsonb       bool   // db:",jsonb" binds and scans the field as JSON
+	generated   bool   // db:",generated=expr" columns are computed and never written
+	grouping    string // db:",grouping=col" selects GROUPING(col) and is never written
+	transform   string // db:",encrypt" and db:",transform=name" convert the value, see Transformer
+	sensitive   bool   // db:",sensitive" values are redacted from DebugString and RedactedArgs
+	auto        autoField
+	typ         reflect.Type
+	options     []string
+}
+
+// NullPolicy controls how zero values of struct fields are bound as named args
+type NullPolicy int
+
+const (
+	// ZeroAsValue binds zero values as they are; fields tagged null still bind NULL
+	ZeroAsValue NullPolicy = iota
+	// ZeroAsNull binds zero values as NULL; fields tagged notnullzero still bind their zero value
+	ZeroAsNull
+)
+
+// bindValue returns the value bound for a struct field under policy
+func (f fieldInfo) bindValue(val reflect.Value, policy NullPolicy) (any, error) {
+	field := val.Field(f.index)
+	if (f.null || (policy == ZeroAsNull && !f.notNullZero)) && field.IsZero() {
+		return nil, nil
+	}
+	if f.jsonb {
+		return jsonValue(field), nil
+	}
+	if f.transform != "" {
+		value, err := bindTransformed(f.transform, field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		return value, nil
+	}
+	if enum := enumOf(field.Type()); enum != nil {
+		if err := enum.validate(field); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	return field.Interface(), nil
+}
+
+// jsonValue marshals a jsonb field. Nil maps, slices and pointers bind as NULL; values that
+// fail to marshal are bound unchanged so the driver reports the error.
+func jsonValue(field reflect.Value) any {
+	switch field.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr, reflect.Interface:
+		if field.IsNil() {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(field.Interface())
+	if err != nil {
+		return field.Interface()
+	}
+	return string(data)
+}
+
+// jsonScanner scans a json or jsonb column into the field it points to
+type jsonScanner struct {
+	dest any
+}
+
+func (s jsonScanner) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		// Reset the destination to its zero value
+		dest := reflect.ValueOf(s.dest).Elem()
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a jsonb field", src)
+	}
+	return json.Unmarshal(data, s.dest)
+}
+
+// parseTag splits a db tag into the column name and its options. Commas inside parentheses
+// or single quotes don't split options, so expressions like generated=coalesce(a, b) survive.
+func parseTag(tag string) (string, []string) {
+	var parts []string
+	depth, quoted, start := 0, false, 0
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, tag[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, tag[start:])
+
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return parts[0], parts[1:]
+}
+
+// optionValue returns the value of a key=value tag option
+func optionValue(options []string, key string) (string, bool) {
+	for _, o := range options {
+		if k, v, ok := strings.Cut(strings.TrimSpace(o), "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// hasOption reports whether a parsed tag has option opt
+func hasOption(options []string, opt string) bool {
+	for _, o := range options {
+		if strings.TrimSpace(o) == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// structInfo holds the column mapping of a struct type
+type structInfo struct {
+	fields []fieldInfo
+	names  []string
+	// selects lists the SELECT items of the fields, in field order
+	selects []string
+	// nested lists the db:",nested" struct fields, scanned from prefixed columns
+	nested []fieldInfo
+	// writable lists the columns that can be inserted or updated
+	writable []string
+}
+
+// structCache caches structInfo by reflect.Type so tags are only parsed once per type
+var structCache sync.Map
+
+// structValue dereferences obj and reports whether it is a struct
+func structValue(obj any) (reflect.Value, bool) {
+	val := reflect.ValueOf(obj)
+
+	// If pointer, get the underlying value
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	return val, val.Kind() == reflect.Struct
+}
+
+// structInfoOf returns the cached column mapping for a struct type.
+// Columns are named by the db tag, then the json tag, then the field name;
+// fields tagged db:"-" (or json:"-" without a db tag) are skipped.
+func structInfoOf(typ reflect.Type) *structInfo {
+	if info, ok := structCache.Load(typ); ok {
+		return info.(*structInfo)
+	}
+
+	info := &structInfo{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		// Skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		dbTag := field.Tag.Get("db")
+		if dbTag == "-" {
+			continue
+		}
+
+		var options []string
+		if dbTag != "" {
+			// Split on comma in case there are options like omitempty
+			var dbName string
+			dbName, options = parseTag(dbTag)
+			if dbName != "" {
+				name = dbName
+			}
+		} else if jsonTag := field.Tag.Get("json"); jsonTag != "" {
+			jsonName, _, _ := strings.Cut(jsonTag, ",")
+			if jsonName == "-" {
+				continue
+			}
+			if jsonName != "" {
+				name = jsonName
+			}
+		}
+
+		// Nested structs aren't columns themselves
+		if hasOption(options, "nested") {
+			info.nested = append(info.nested, fieldInfo{name: name, index: i, typ: field.Type, options: options})
+			continue
+		}
+
+		info.fields = append(info.fields, fieldInfo{
+			name:        name,
+			index:       i,
+			null:        hasOption(options, "null"),
+			notNullZero: hasOption(options, "notnullzero"),
+			jsonb:       hasOption(options, "jsonb"),
+			transform:   transformName(options),
+			sensitive:   hasOption(options, "sensitive"),
+			auto:        autoOf(options),
+			typ:         field.Type,
+			options:     options,
+		})
+		info.names = append(info.names, name)
+
+		grouping, isGrouping := optionValue(options, "grouping")
+		if isGrouping {
+			grouping = strings.TrimSuffix(strings.TrimPrefix(grouping, "("), ")")
+			info.fields[len(info.fields)-1].grouping = grouping
+			info.selects = append(info.selects, "GROUPING("+grouping+") AS "+quoteColumn(name))
+		} else {
+			info.selects = append(info.selects, name)
+		}
+
+		if _, generated := optionValue(options, "generated"); generated {
+			info.fields[len(info.fields)-1].generated = true
+		} else if !isGrouping {
+			info.writable = append(info.writable, name)
+		}
+	}
+
+	actual, _ := structCache.LoadOrStore(typ, info)
+	return actual.(*structInfo)
+}
+
+// StructField describes a struct field mapped to a column, or a nested struct whose fields are
+// selected with a prefix (see SelectPrefixed)
+type StructField struct {
+	Column  string // column name, or the column prefix of a nested struct
+	Index   int    // index of the field in the struct
+	Type    reflect.Type
+	Options []string
+	Nested  bool // db:",nested" struct scanned from prefixed columns
+}
+
+// StructFields returns the column mapping of a struct type in field order, following the
+// same naming rules as the builders, for scanning and tooling outside this package
+func StructFields(typ reflect.Type) []StructField {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	info := structInfoOf(typ)
+	fields := make([]StructField, 0, len(info.fields)+len(info.nested))
+	for _, f := range info.fields {
+		fields = append(fields, StructField{Column: f.name, Index: f.index, Type: f.typ, Options: f.options})
+	}
+	for _, f := range info.nested {
+		fields = append(fields, StructField{Column: f.name, Index: f.index, Type: f.typ, Options: f.options, Nested: true})
+	}
+	slices.SortFunc(fields, func(a, b StructField) int { return a.Index - b.Index })
+	return fields
+}
+
+// HasOption reports whether the field's tag has option opt
+func (f StructField) HasOption(opt string) bool {
+	return hasOption(f.Options, opt)
+}
+
+// OptionValue returns the value of a key=value tag option
+func (f StructField) OptionValue(key string) (string, bool) {
+	return optionValue(f.Options, key)
+}
+
+// Pointer returns the scan destination of the field in the addressable struct val.
+// jsonb fields are wrapped in a sql.Scanner that unmarshals the column's JSON.
+func (f StructField) Pointer(val reflect.Value) any {
+	ptr := val.Field(f.Index).Addr().Interface()
+	if f.HasOption("jsonb") {
+		return jsonScanner{dest: ptr}
+	}
+	if name := transformName(f.Options); name != "" {
+		return transformScanner{dest: ptr, name: name}
+	}
+	return ptr
+}
@@ -0,0 +1,38 @@
+package pgstring
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Fingerprint returns a hash of the statement's SQL text, equal for statements of the same
+// shape whatever their args. Args are never part of it, even when inlined, so it is safe to
+// log for statements with sensitive args.
+func (pg PgString) Fingerprint() string {
+	sum := sha256.Sum256([]byte(pg.render()))
+	return hex.EncodeToString(sum[:16])
+}
+
+// CacheKey returns a hash of the statement's SQL text and args, equal for statements that
+// return the same results
+func (pg PgString) CacheKey() string {
+	h := sha256.New()
+	h.Write([]byte(pg.render()))
+
+	args := pg.namedArgs()
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		h.Write([]byte{0})
+		h.Write([]byte(name))
+		h.Write([]byte{'='})
+		if data, err := json.Marshal(args[name]); err == nil {
+			h.Write(data)
+		} else {
+			fmt.Fprintf(h, "%#v", args[name])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
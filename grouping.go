@@ -0,0 +1,32 @@
+package pgstring
+
+import "strings"
+
+// GroupByRollUp adds GROUP BY ROLLUP (columns), producing subtotals for each prefix of
+// columns and a grand total
+func (pg PgString) GroupByRollUp(columns ...string) PgString {
+	return pg.with(clause{kind: clauseGroupBy, sql: "ROLLUP (" + strings.Join(columns, ", ") + ")"})
+}
+
+// GroupByCube adds GROUP BY CUBE (columns), producing subtotals for every combination of columns
+func (pg PgString) GroupByCube(columns ...string) PgString {
+	return pg.with(clause{kind: clauseGroupBy, sql: "CUBE (" + strings.Join(columns, ", ") + ")"})
+}
+
+// GroupByGroupingSets adds GROUP BY GROUPING SETS with one grouping per set; an empty set
+// is the grand total
+func (pg PgString) GroupByGroupingSets(sets ...[]string) PgString {
+	groups := make([]string, len(sets))
+	for i, set := range sets {
+		groups[i] = "(" + strings.Join(set, ", ") + ")"
+	}
+	return pg.with(clause{kind: clauseGroupBy, sql: "GROUPING SETS (" + strings.Join(groups, ", ") + ")"})
+}
+
+// Grouping returns GROUPING(columns), a bit mask telling which columns a result row is
+// aggregated over, so subtotal rows can be told apart from NULL values. Struct fields
+// tagged db:"name,grouping=col" or db:"name,grouping=(a, b)" select it as name when
+// the struct is passed to Select and scan it like any other column.
+func Grouping(columns ...string) Expr {
+	return Expr{sql: "GROUPING(" + strings.Join(columns, ", ") + ")"}
+}
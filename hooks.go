@@ -0,0 +1,106 @@
+package pgstring
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// BeforeInserter is implemented by models with logic to run before they are inserted,
+// such as generating a UUID or normalizing an email. Values, ValuesFrom, ValuesBulk,
+// InsertObj and Upsert call it; an error fails the statement.
+type BeforeInserter interface {
+	BeforeInsert() error
+}
+
+// BeforeUpdater is implemented by models with logic to run before they are updated, such
+// as setting updated_at. Set and SetDiff call it, except in an upsert's DO UPDATE SET,
+// where Set runs BeforeInsert again so it binds the values VALUES does; hooks should
+// therefore be idempotent.
+type BeforeUpdater interface {
+	BeforeUpdate() error
+}
+
+// Validator is implemented by models that check themselves before they are written. Values,
+// ValuesFrom, ValuesBulk, Set and SetDiff call Validate after the Before hooks, and an error
+// fails the statement with a ValidationError before any SQL is generated.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError is the error of a model whose Validate failed
+type ValidationError struct {
+	Model any
+	Err   error
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", modelType(e.Model), e.Err)
+}
+
+func (e ValidationError) Unwrap() error { return e.Err }
+
+// beforeInsert fills the auto fields of obj, runs its BeforeInsert hook and validates it,
+// returning the model to bind
+func beforeInsert(obj any) (any, error) {
+	obj, err := fillAuto(obj, true)
+	if err != nil {
+		return obj, err
+	}
+	obj, err = runHook(obj, BeforeInserter.BeforeInsert)
+	if err != nil {
+		return obj, fmt.Errorf("BeforeInsert: %w", err)
+	}
+	return obj, validate(obj)
+}
+
+// beforeUpdate fills the updatedat fields of obj, runs its BeforeUpdate hook and validates
+// it, returning the model to bind
+func beforeUpdate(obj any) (any, error) {
+	obj, err := fillAuto(obj, false)
+	if err != nil {
+		return obj, err
+	}
+	obj, err = runHook(obj, BeforeUpdater.BeforeUpdate)
+	if err != nil {
+		return obj, fmt.Errorf("BeforeUpdate: %w", err)
+	}
+	return obj, validate(obj)
+}
+
+// validate returns a ValidationError when obj is a Validator that fails
+func validate(obj any) error {
+	if _, err := runHook(obj, Validator.Validate); err != nil {
+		return ValidationError{Model: obj, Err: err}
+	}
+	return nil
+}
+
+// runHook calls hook on obj when it implements H. A struct passed by value whose hook has
+// a pointer receiver is copied, so the hook's changes are bound without modifying the
+// caller's value; pointers are modified in place.
+func runHook[H any](obj any, hook func(H) error) (any, error) {
+	if h, ok := obj.(H); ok && !isNilPointer(obj) {
+		return obj, hook(h)
+	}
+
+	val, ok := structValue(obj)
+	if !ok {
+		return obj, nil
+	}
+	ptr := reflect.New(val.Type())
+	h, ok := ptr.Interface().(H)
+	if !ok {
+		return obj, nil
+	}
+	ptr.Elem().Set(val)
+	return ptr.Interface(), hook(h)
+}
+
+// elemModel returns an element of a ValuesBulk slice as a model for hooks, addressing
+// struct elements so their hooks modify the slice in place
+func elemModel(elem reflect.Value) any {
+	if elem.Kind() == reflect.Struct && elem.CanAddr() {
+		return elem.Addr().Interface()
+	}
+	return elem.Interface()
+}
@@ -0,0 +1,51 @@
+package pgstring_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+type signup struct {
+	ID    int    `db:"id,primarykey"`
+	Email string `db:"email"`
+}
+
+func (s *signup) BeforeInsert() error {
+	s.Email = strings.ToLower(s.Email)
+	return nil
+}
+
+func (s signup) TableName() string { return "signups" }
+
+func (s signup) Validate() error {
+	if s.Email == "" {
+		return errors.New("email is required")
+	}
+	return nil
+}
+
+func TestBeforeInsert(t *testing.T) {
+	assertSQL(t, pgstring.InsertInto("signups").Obj(signup{}).Values(signup{ID: 1, Email: "Ada@Example.com"}),
+		"INSERT INTO signups (id, email) VALUES (@id, @email)",
+		map[string]any{"id": 1, "email": "ada@example.com"})
+}
+
+func TestBeforeInsertUpsertRunsOnce(t *testing.T) {
+	assertSQL(t, pgstring.Upsert(signup{ID: 1, Email: "Ada@Example.com"}),
+		"INSERT INTO signups (id, email) VALUES (@id, @email) ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email",
+		map[string]any{"id": 1, "email": "ada@example.com"})
+}
+
+func TestValidate(t *testing.T) {
+	_, _, err := pgstring.InsertObj(signup{ID: 1}).Build()
+	var invalid pgstring.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Build error = %v, want a ValidationError", err)
+	}
+	if invalid.Err.Error() != "email is required" {
+		t.Errorf("got %v", invalid.Err)
+	}
+}
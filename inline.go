@@ -0,0 +1,155 @@
+package pgstring
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// inlineArgsAll makes Build inline args for every query
+var inlineArgsAll atomic.Bool
+
+// SetInlineArgs makes Build inline the args of every query as literals (see InlineArgs),
+// for services behind a transaction-pooling pgbouncer
+func SetInlineArgs(enabled bool) {
+	inlineArgsAll.Store(enabled)
+}
+
+// InlineArgs makes Build return this query with its args inlined as quoted literals and no
+// args, so it can run over the simple query protocol where prepared statements aren't
+// available, as behind pgbouncer in transaction pooling mode. Strings are quoted for
+// standard_conforming_strings = on, the default since Postgres 9.1. Values of types
+// without a literal form make Build fail.
+func (pg PgString) InlineArgs() PgString {
+	pg.inline = true
+	return pg
+}
+
+// inlineSQL replaces every placeholder of sql with the literal of its arg
+func inlineSQL(sql string, args map[string]any) (string, error) {
+	var b strings.Builder
+	var err error
+	last := 0
+	scanPlaceholders(sql, func(start, end int) {
+		if err != nil {
+			return
+		}
+		name := sql[start+1 : end]
+		value, ok := args[name]
+		if !ok {
+			err = fmt.Errorf("missing arg for placeholder @%s", name)
+			return
+		}
+		var literal string
+		if literal, err = inlineLiteral(value); err != nil {
+			err = fmt.Errorf("arg @%s: %w", name, err)
+			return
+		}
+		b.WriteString(sql[last:start])
+		b.WriteString(literal)
+		last = end
+	})
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(sql[last:])
+	return b.String(), nil
+}
+
+// inlineLiteral renders value as a SQL literal that is safe to execute
+func inlineLiteral(value any) (string, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		if _, again := v.(driver.Valuer); again {
+			return "", fmt.Errorf("%T returns another driver.Valuer", value)
+		}
+		value = v
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		if strings.ContainsRune(v, 0) {
+			return "", errors.New("strings can't contain NUL bytes")
+		}
+		return quoteLiteral(v), nil
+	case []byte:
+		return `'\x` + hex.EncodeToString(v) + "'::bytea", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case time.Time:
+		return quoteLiteral(v.Format("2006-01-02 15:04:05.999999Z07:00")) + "::timestamptz", nil
+	case time.Duration:
+		return quoteLiteral(strconv.FormatInt(v.Microseconds(), 10)+" microseconds") + "::interval", nil
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return "NULL", nil
+		}
+		return inlineLiteral(val.Elem().Interface())
+	case reflect.String:
+		return inlineLiteral(val.String())
+	case reflect.Bool:
+		return inlineLiteral(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return enclosedNumber(strconv.FormatInt(val.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := val.Float()
+		switch {
+		case math.IsNaN(f):
+			return "'NaN'::float8", nil
+		case math.IsInf(f, 1):
+			return "'Infinity'::float8", nil
+		case math.IsInf(f, -1):
+			return "'-Infinity'::float8", nil
+		}
+		return enclosedNumber(strconv.FormatFloat(f, 'g', -1, val.Type().Bits())), nil
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return "NULL", nil
+		}
+		if val.Len() == 0 {
+			// ARRAY[] needs a type, an untyped literal takes it from the context
+			return "'{}'", nil
+		}
+		items := make([]string, val.Len())
+		for i := range items {
+			item, err := inlineLiteral(val.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return "ARRAY[" + strings.Join(items, ", ") + "]", nil
+	default:
+		return "", fmt.Errorf("%T has no literal form", value)
+	}
+}
+
+// enclosedNumber parenthesizes a negative number, so inlining it after an operator like
+// price - @delta can't form a -- comment
+func enclosedNumber(number string) string {
+	if strings.HasPrefix(number, "-") {
+		return "(" + number + ")"
+	}
+	return number
+}
@@ -0,0 +1,48 @@
+package pgstring_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestInlineArgs(t *testing.T) {
+	var missing *int
+	when := time.Date(2024, 5, 1, 12, 30, 0, 500000000, time.UTC)
+	for _, tc := range []struct {
+		value any
+		want  string
+	}{
+		{-5, "price - (-5)"},
+		{int8(-1), "price - (-1)"},
+		{-2.5, "price - (-2.5)"},
+		{7, "price - 7"},
+		{uint(3), "price - 3"},
+		{math.NaN(), "price - 'NaN'::float8"},
+		{math.Inf(-1), "price - '-Infinity'::float8"},
+		{`it's a \ path`, `price - 'it''s a \ path'`},
+		{[]byte{0xde, 0xad}, `price - '\xdead'::bytea`},
+		{when, "price - '2024-05-01 12:30:00.5Z'::timestamptz"},
+		{90 * time.Second, "price - '90000000 microseconds'::interval"},
+		{nil, "price - NULL"},
+		{missing, "price - NULL"},
+		{true, "price - TRUE"},
+		{[]int{1, -2}, "price - ARRAY[1, (-2)]"},
+		{[]string{}, "price - '{}'"},
+	} {
+		assertSQL(t, pgstring.Select("price - @delta").Where("TRUE", map[string]any{"delta": tc.value}).InlineArgs(),
+			"SELECT "+tc.want+" WHERE TRUE", nil)
+	}
+}
+
+func TestInlineArgsErrors(t *testing.T) {
+	assertErr(t, pgstring.Select("@s").Where("TRUE", map[string]any{"s": "a\x00b"}).InlineArgs(), "NUL")
+	assertErr(t, pgstring.Select("@c").Where("TRUE", map[string]any{"c": make(chan int)}).InlineArgs(), "no literal form")
+}
+
+func TestInlineArgsSkipsQuoted(t *testing.T) {
+	assertSQL(t, pgstring.Select("'@id', @id").Where("TRUE", map[string]any{"id": -1}).InlineArgs(),
+		"SELECT '@id', (-1) WHERE TRUE", nil)
+}
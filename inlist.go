@@ -0,0 +1,97 @@
+package pgstring
+
+import (
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SliceMode controls how slice values in condition arg maps are bound to IN (@name) lists
+type SliceMode int
+
+const (
+	// ExpandSlices binds each element as its own parameter: IN (@ids_0, @ids_1, ...).
+	// Empty slices and slices past MaxParams are bound as arrays instead.
+	ExpandSlices SliceMode = iota
+	// ArraySlices binds the whole slice as one array parameter: = ANY(@ids)
+	ArraySlices
+)
+
+// SliceMode sets how slice values in the arg maps of later Where, Having and Join calls are bound
+// where the condition uses them as IN (@name). The default is ExpandSlices.
+func (pg PgString) SliceMode(mode SliceMode) PgString {
+	pg.sliceMode = mode
+	return pg
+}
+
+// expandArgs applies expandInLists to the args of a condition when they are a map. The
+// caller's args slice is left as is.
+func (pg PgString) expandArgs(condition string, args []any) (string, []any) {
+	if len(args) != 1 {
+		return condition, args
+	}
+	values, ok := args[0].(map[string]any)
+	if !ok {
+		return condition, args
+	}
+	condition, expanded := pg.expandInLists(condition, values)
+	return condition, []any{expanded}
+}
+
+// expandInLists rewrites each IN (@name) of condition whose arg in args is a slice, as an
+// expanded parameter list or an array comparison. NOT IN becomes <> ALL(@name) as an array.
+// Other uses of a slice arg, like = ANY(@name), are left as they are.
+func (pg PgString) expandInLists(condition string, args map[string]any) (string, map[string]any) {
+	var expanded map[string]any
+	// Names are visited in order so the MaxParams fallback picks the same lists every run
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		val := reflect.ValueOf(args[name])
+		if val.Kind() != reflect.Slice && val.Kind() != reflect.Array || val.Type().Elem().Kind() == reflect.Uint8 {
+			continue
+		}
+		in := regexp.MustCompile(`(?i)\b(NOT\s+)?IN\s*\(\s*@` + regexp.QuoteMeta(name) + `\s*\)`)
+		if !in.MatchString(condition) {
+			continue
+		}
+
+		if expanded == nil {
+			expanded = make(map[string]any, len(args))
+			for k, v := range args {
+				expanded[k] = v
+			}
+		}
+
+		if pg.sliceMode == ArraySlices || val.Len() == 0 || pg.paramCount()+len(expanded)+val.Len() > MaxParams {
+			condition = in.ReplaceAllStringFunc(condition, func(match string) string {
+				if in.FindStringSubmatch(match)[1] != "" {
+					return "<> ALL(@" + name + ")"
+				}
+				return "= ANY(@" + name + ")"
+			})
+			continue
+		}
+
+		placeholders := make([]string, val.Len())
+		for i := range placeholders {
+			key := name + "_" + strconv.Itoa(i)
+			placeholders[i] = "@" + key
+			expanded[key] = val.Index(i).Interface()
+		}
+		delete(expanded, name)
+		list := "(" + strings.Join(placeholders, ", ") + ")"
+		condition = in.ReplaceAllStringFunc(condition, func(match string) string {
+			if in.FindStringSubmatch(match)[1] != "" {
+				return "NOT IN " + list
+			}
+			return "IN " + list
+		})
+	}
+
+	if expanded == nil {
+		return condition, args
+	}
+	return condition, expanded
+}
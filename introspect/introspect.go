@@ -0,0 +1,125 @@
+// Package introspect provides prebuilt catalog queries describing an existing database.
+// Each query selects columns in the field order of its result struct, so rows can be
+// scanned with pgstring.GenerateFieldPointers.
+package introspect
+
+import (
+	"strings"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+// Table is a row returned by ListTables
+type Table struct {
+	Schema string `db:"table_schema"`
+	Name   string `db:"table_name"`
+	Type   string `db:"table_type"` // BASE TABLE, VIEW, FOREIGN or LOCAL TEMPORARY
+}
+
+// Column is a row returned by ListColumns
+type Column struct {
+	Schema    string  `db:"table_schema"`
+	Table     string  `db:"table_name"`
+	Name      string  `db:"column_name"`
+	Position  int     `db:"ordinal_position"`
+	DataType  string  `db:"data_type"`
+	UDTName   string  `db:"udt_name"`
+	Nullable  bool    `db:"is_nullable"`
+	Default   *string `db:"column_default"`
+	MaxLength *int    `db:"character_maximum_length"`
+}
+
+// Index is a row returned by ListIndexes
+type Index struct {
+	Schema     string `db:"schema"`
+	Table      string `db:"table"`
+	Name       string `db:"name"`
+	Unique     bool   `db:"is_unique"`
+	Primary    bool   `db:"is_primary"`
+	Definition string `db:"definition"`
+}
+
+// Constraint is a row returned by ListConstraints
+type Constraint struct {
+	Name       string `db:"name"`
+	Type       string `db:"type"` // PRIMARY KEY, FOREIGN KEY, UNIQUE, CHECK or EXCLUDE
+	Definition string `db:"definition"`
+}
+
+// ListTables lists the tables and views of schema
+func ListTables(schema string) pgstring.PgString {
+	return pgstring.Select([]string{
+		"table_schema::text",
+		"table_name::text",
+		"table_type::text",
+	}).
+		From("information_schema.tables").
+		Where("table_schema = @schema", map[string]any{"schema": schema}).
+		OrderBy("table_name")
+}
+
+// ListColumns lists the columns of table in ordinal order. table may be schema-qualified;
+// unqualified names are looked up in the current schema.
+func ListColumns(table string) pgstring.PgString {
+	pg := pgstring.Select([]string{
+		"table_schema::text",
+		"table_name::text",
+		"column_name::text",
+		"ordinal_position::int",
+		"data_type::text",
+		"udt_name::text",
+		"is_nullable = 'YES' AS is_nullable",
+		"column_default::text",
+		"character_maximum_length::int",
+	}).From("information_schema.columns")
+
+	schema, name := splitTable(table)
+	if schema == "" {
+		pg = pg.Where("table_schema = current_schema()")
+	} else {
+		pg = pg.Where("table_schema = @schema", map[string]any{"schema": schema})
+	}
+
+	return pg.Where("table_name = @table", map[string]any{"table": name}).
+		OrderBy("ordinal_position")
+}
+
+// ListIndexes lists the indexes of table, resolved through the search path like any
+// table reference
+func ListIndexes(table string) pgstring.PgString {
+	return pgstring.Select([]string{
+		"n.nspname::text AS schema",
+		"t.relname::text AS table",
+		"i.relname::text AS name",
+		"ix.indisunique AS is_unique",
+		"ix.indisprimary AS is_primary",
+		"pg_get_indexdef(ix.indexrelid) AS definition",
+	}).
+		From("pg_index ix").
+		Join("INNER", "pg_class i", "i.oid = ix.indexrelid").
+		Join("INNER", "pg_class t", "t.oid = ix.indrelid").
+		Join("INNER", "pg_namespace n", "n.oid = t.relnamespace").
+		Where("ix.indrelid = @table::regclass", map[string]any{"table": table}).
+		OrderBy("i.relname")
+}
+
+// ListConstraints lists the constraints of table
+func ListConstraints(table string) pgstring.PgString {
+	return pgstring.Select([]string{
+		"conname::text AS name",
+		"CASE contype WHEN 'p' THEN 'PRIMARY KEY' WHEN 'f' THEN 'FOREIGN KEY' WHEN 'u' THEN 'UNIQUE' " +
+			"WHEN 'c' THEN 'CHECK' WHEN 'x' THEN 'EXCLUDE' ELSE contype::text END AS type",
+		"pg_get_constraintdef(oid) AS definition",
+	}).
+		From("pg_constraint").
+		Where("conrelid = @table::regclass", map[string]any{"table": table}).
+		OrderBy("conname")
+}
+
+// splitTable splits an optionally schema-qualified table name
+func splitTable(table string) (schema, name string) {
+	if i := strings.LastIndexByte(table, '.'); i >= 0 {
+		return table[:i], table[i+1:]
+	}
+	return "", table
+}
@@ -0,0 +1,54 @@
+package pgstring
+
+import "strings"
+
+// NonTransactional marks the statement as one Postgres refuses to run inside a transaction
+// block, such as CREATE INDEX CONCURRENTLY. pgexec rejects it when given a transaction and
+// migrate runs it directly on the database.
+func (pg PgString) NonTransactional() PgString {
+	pg.noTx = true
+	return pg
+}
+
+// IsNonTransactional reports whether the statement must run outside a transaction
+func (pg PgString) IsNonTransactional() bool {
+	return pg.noTx
+}
+
+// CreateIndexConcurrently creates a CREATE INDEX CONCURRENTLY statement, which builds the
+// index without blocking writes to table
+func CreateIndexConcurrently(name, table string, columns ...string) PgString {
+	return createIndexConcurrently("INDEX", name, table, columns)
+}
+
+// CreateUniqueIndexConcurrently creates a CREATE UNIQUE INDEX CONCURRENTLY statement
+func CreateUniqueIndexConcurrently(name, table string, columns ...string) PgString {
+	return createIndexConcurrently("UNIQUE INDEX", name, table, columns)
+}
+
+func createIndexConcurrently(kind, name, table string, columns []string) PgString {
+	sql := "CREATE " + kind + " CONCURRENTLY " + name + " ON " + table + " (" + strings.Join(columns, ", ") + ")"
+	return ddl(sql, table).NonTransactional()
+}
+
+// DropIndexConcurrently creates a DROP INDEX CONCURRENTLY IF EXISTS statement
+func DropIndexConcurrently(name string) PgString {
+	return ddl("DROP INDEX CONCURRENTLY IF EXISTS "+name, "").NonTransactional()
+}
+
+// RefreshMaterializedView creates a REFRESH MATERIALIZED VIEW statement. A concurrent
+// refresh keeps the view readable but requires a unique index on it.
+func RefreshMaterializedView(view string, concurrently bool) PgString {
+	if concurrently {
+		return ddl("REFRESH MATERIALIZED VIEW CONCURRENTLY "+view, view)
+	}
+	return ddl("REFRESH MATERIALIZED VIEW "+view, view)
+}
+
+// RefreshConcurrently sequences the unique index a concurrent refresh needs, built
+// concurrently if it doesn't exist yet, followed by the refresh itself
+func RefreshConcurrently(view, index string, columns ...string) Script {
+	createIndex := ddl("CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS "+index+" ON "+view+
+		" ("+strings.Join(columns, ", ")+")", view).NonTransactional()
+	return NewScript(createIndex, RefreshMaterializedView(view, true))
+}
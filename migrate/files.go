@@ -0,0 +1,114 @@
+// Package migrate writes PgString DDL into migration files and runs migrations.
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+// VersionFormat is the timestamp layout used as the migration version
+const VersionFormat = "20060102150405"
+
+// Style selects the file naming convention of a migration runner
+type Style int
+
+const (
+	// GolangMigrate writes {version}_{name}.up.sql and {version}_{name}.down.sql
+	GolangMigrate Style = iota
+	// Goose writes a single {version}_{name}.sql with -- +goose Up/Down sections
+	Goose
+)
+
+// Migration is a named set of statements to apply and to roll back.
+// Version orders migrations in a Runner and is recorded once applied.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []pgstring.PgString
+	Down    []pgstring.PgString
+}
+
+// WriteFiles writes m into dir using style, versioned by at, and returns the paths written.
+// Statements must not bind named args, since migration files are executed as plain SQL.
+func WriteFiles(dir string, m Migration, style Style, at time.Time) ([]string, error) {
+	up, err := renderStatements(m.Up)
+	if err != nil {
+		return nil, fmt.Errorf("%s up: %w", m.Name, err)
+	}
+	down, err := renderStatements(m.Down)
+	if err != nil {
+		return nil, fmt.Errorf("%s down: %w", m.Name, err)
+	}
+
+	base := at.UTC().Format(VersionFormat) + "_" + fileName(m.Name)
+
+	var files []migrationFile
+	switch style {
+	case GolangMigrate:
+		files = []migrationFile{
+			{name: base + ".up.sql", contents: up},
+			{name: base + ".down.sql", contents: down},
+		}
+	case Goose:
+		files = []migrationFile{
+			{name: base + ".sql", contents: "-- +goose Up\n" + up + "\n-- +goose Down\n" + down},
+		}
+	default:
+		return nil, fmt.Errorf("unknown migration style %d", style)
+	}
+
+	var paths []string
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		if err := os.WriteFile(path, []byte(file.contents), 0o644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// migrationFile is a file to be written by WriteFiles
+type migrationFile struct {
+	name     string
+	contents string
+}
+
+// renderStatements renders statements as a script terminated by semicolons
+func renderStatements(statements []pgstring.PgString) (string, error) {
+	var b strings.Builder
+	for _, statement := range statements {
+		sql, args, err := statement.Build()
+		if err != nil {
+			return "", err
+		}
+		if len(args) > 0 {
+			return "", fmt.Errorf("statement binds named args and cannot be written to a file: %s", sql)
+		}
+
+		b.WriteString(strings.TrimRight(strings.TrimSpace(sql), ";"))
+		b.WriteString(";\n")
+	}
+	return b.String(), nil
+}
+
+// fileName converts a migration name into the snake_case form used in file names
+func fileName(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
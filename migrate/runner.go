@@ -0,0 +1,279 @@
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oliverpaddock/pgstring"
+	"github.com/oliverpaddock/pgstring/pgexec"
+)
+
+// DefaultTable is the tracking table used when none is configured
+const DefaultTable = "schema_migrations"
+
+// appliedMigration is a row of the tracking table
+type appliedMigration struct {
+	Version   int64     `db:"version,primarykey"`
+	Name      string    `db:"name,notnull"`
+	AppliedAt time.Time `db:"applied_at,notnull"`
+}
+
+// Runner applies and rolls back migrations, recording applied versions in a tracking table.
+// Each migration runs in its own transaction holding a transaction-level advisory lock,
+// so concurrent runners (for example several instances starting at once) apply it only once.
+// Migrations containing NonTransactional statements (CREATE INDEX CONCURRENTLY) run
+// directly on the database instead, holding a session-level advisory lock on a single
+// connection (acquired from db when it is a *pgxpool.Pool); keep them in migrations of
+// their own.
+type Runner struct {
+	db         pgexec.DB
+	table      string
+	migrations []Migration
+}
+
+// NewRunner creates a Runner for migrations. Migrations are applied in ascending Version order.
+func NewRunner(db pgexec.DB, migrations ...Migration) *Runner {
+	sorted := slices.Clone(migrations)
+	slices.SortStableFunc(sorted, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return &Runner{db: db, table: DefaultTable, migrations: sorted}
+}
+
+// Table sets the name of the tracking table
+func (r *Runner) Table(name string) *Runner {
+	r.table = name
+	return r
+}
+
+// Up applies every pending migration and returns the ones it applied
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	if err := r.init(ctx); err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range r.migrations {
+		ok, err := r.apply(ctx, m)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		if ok {
+			applied = append(applied, m)
+		}
+	}
+	return applied, nil
+}
+
+// Down rolls back the most recently applied migration. It returns nil if nothing was applied.
+func (r *Runner) Down(ctx context.Context) (*Migration, error) {
+	if err := r.init(ctx); err != nil {
+		return nil, err
+	}
+
+	var rolledBack *Migration
+	err := r.locked(ctx, func(tx pgx.Tx) error {
+		var version int64
+		latest := pgstring.Select("version").From(r.table).OrderBy("version DESC").Limit(1)
+		if err := pgexec.QueryRow(ctx, tx, latest).Scan(&version); errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		i := slices.IndexFunc(r.migrations, func(m Migration) bool { return m.Version == version })
+		if i < 0 {
+			return fmt.Errorf("applied migration %d is not registered", version)
+		}
+
+		m := r.migrations[i]
+		if nonTransactional(m.Down) {
+			// Rolled back below, outside the transaction
+			rolledBack = &m
+			return nil
+		}
+		if err := execAll(ctx, tx, m.Down); err != nil {
+			return fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+
+		remove := pgstring.Delete().From(r.table).Where("version = @version", map[string]any{"version": version})
+		if _, err := pgexec.Exec(ctx, tx, remove); err != nil {
+			return err
+		}
+		rolledBack = &m
+		return nil
+	})
+	if err != nil || rolledBack == nil || !nonTransactional(rolledBack.Down) {
+		return rolledBack, err
+	}
+
+	m := rolledBack
+	rolledBack = nil
+	err = r.sessionLocked(ctx, func(db pgexec.DB) error {
+		// Another runner may have rolled it back since the transaction above
+		if ok, err := r.isApplied(ctx, db, m.Version); err != nil || !ok {
+			return err
+		}
+		if err := execAll(ctx, db, m.Down); err != nil {
+			return fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		remove := pgstring.Delete().From(r.table).Where("version = @version", map[string]any{"version": m.Version})
+		if _, err := pgexec.Exec(ctx, db, remove); err != nil {
+			return err
+		}
+		rolledBack = m
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Applied returns the versions recorded in the tracking table in ascending order
+func (r *Runner) Applied(ctx context.Context) ([]int64, error) {
+	if err := r.init(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := pgexec.Query(ctx, r.db, pgstring.Select("version").From(r.table).OrderBy("version"))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// init creates the tracking table if it doesn't exist
+func (r *Runner) init(ctx context.Context) error {
+	return r.locked(ctx, func(tx pgx.Tx) error {
+		_, err := pgexec.Exec(ctx, tx, pgstring.CreateTable(r.table, appliedMigration{}, pgstring.TableOptionIfNotExists))
+		return err
+	})
+}
+
+// apply runs m if it hasn't been applied yet and reports whether it ran
+func (r *Runner) apply(ctx context.Context, m Migration) (bool, error) {
+	if nonTransactional(m.Up) {
+		return r.applyDirect(ctx, m)
+	}
+
+	ran := false
+	err := r.locked(ctx, func(tx pgx.Tx) error {
+		if ok, err := r.isApplied(ctx, tx, m.Version); err != nil || ok {
+			return err
+		}
+
+		if err := execAll(ctx, tx, m.Up); err != nil {
+			return err
+		}
+
+		record := appliedMigration{Version: m.Version, Name: m.Name, AppliedAt: pgstring.ClockNow().UTC()}
+		if _, err := pgexec.Exec(ctx, tx, pgstring.InsertInto(r.table).Obj(record).Values(record)); err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	})
+	return ran, err
+}
+
+// applyDirect runs m outside a transaction. A concurrent index build waits for every open
+// transaction, so holding the advisory lock's transaction meanwhile would deadlock; the
+// session-level lock holds no transaction open.
+func (r *Runner) applyDirect(ctx context.Context, m Migration) (bool, error) {
+	ran := false
+	err := r.sessionLocked(ctx, func(db pgexec.DB) error {
+		if ok, err := r.isApplied(ctx, db, m.Version); err != nil || ok {
+			return err
+		}
+
+		if err := execAll(ctx, db, m.Up); err != nil {
+			return err
+		}
+
+		record := appliedMigration{Version: m.Version, Name: m.Name, AppliedAt: pgstring.ClockNow().UTC()}
+		if _, err := pgexec.Exec(ctx, db, pgstring.InsertInto(r.table).Obj(record).Values(record)); err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	})
+	return ran, err
+}
+
+// isApplied reports whether version is recorded in the tracking table
+func (r *Runner) isApplied(ctx context.Context, db pgexec.Querier, version int64) (bool, error) {
+	exists := pgstring.Select("version").From(r.table).Where("version = @version", map[string]any{"version": version})
+	if err := pgexec.QueryRow(ctx, db, exists).Scan(&version); errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// locked runs fn in a transaction holding the runner's advisory lock
+func (r *Runner) locked(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockKey(r.table)); err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// sessionLocked runs fn outside a transaction on one connection holding the runner's
+// advisory lock at session level, which conflicts with the transaction-level lock of locked
+func (r *Runner) sessionLocked(ctx context.Context, fn func(db pgexec.DB) error) error {
+	db := r.db
+	if pool, ok := db.(*pgxpool.Pool); ok {
+		// The lock and its unlock must run on the same connection
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Release()
+		db = conn
+	}
+
+	if _, err := db.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey(r.table)); err != nil {
+		return err
+	}
+	// Unlock even when ctx is canceled, so the pooled connection doesn't keep the lock
+	defer db.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey(r.table))
+	return fn(db)
+}
+
+// execAll executes statements in order
+func execAll(ctx context.Context, db pgexec.Querier, statements []pgstring.PgString) error {
+	for _, statement := range statements {
+		if _, err := pgexec.Exec(ctx, db, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nonTransactional reports whether any statement must run outside a transaction
+func nonTransactional(statements []pgstring.PgString) bool {
+	return slices.ContainsFunc(statements, pgstring.PgString.IsNonTransactional)
+}
+
+// lockKey derives the advisory lock key from the tracking table name
+func lockKey(table string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("pgstring/migrate:" + table))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,150 @@
+package pgstring
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Null is an optional value for nullable columns, an alternative to pointer fields and the
+// sql.Null* types: the zero Null binds as NULL and NULL scans back as an invalid Null.
+// CreateTable maps a Null[T] field like a T field.
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NullOf returns a valid Null holding v
+func NullOf[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true}
+}
+
+// Get returns the value and whether it is set
+func (n Null[T]) Get() (T, bool) {
+	return n.V, n.Valid
+}
+
+// Value implements driver.Valuer for binding a Null outside the builders. Builders bind
+// the value itself, so pgx encodes it natively, e.g. a Null[[]string] as an array.
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(n.V)
+}
+
+// Scan implements sql.Scanner. NULL resets n; other values are assigned to V when they are
+// of its type or convertible to it, or scanned by V when it is a sql.Scanner.
+func (n *Null[T]) Scan(src any) error {
+	if src == nil {
+		*n = Null[T]{}
+		return nil
+	}
+	if scanner, ok := any(&n.V).(sql.Scanner); ok {
+		if err := scanner.Scan(src); err != nil {
+			return err
+		}
+		n.Valid = true
+		return nil
+	}
+
+	val := reflect.ValueOf(src)
+	typ := reflect.TypeFor[T]()
+	if !val.Type().ConvertibleTo(typ) || val.Kind() != typ.Kind() && !convertsCleanly(val.Kind(), typ.Kind()) {
+		return fmt.Errorf("can't scan %T into Null[%s]", src, typ)
+	}
+	n.V = val.Convert(typ).Interface().(T)
+	n.Valid = true
+	return nil
+}
+
+// convertsCleanly reports whether values of kind from convert to kind to without changing
+// their meaning, unlike e.g. int64 to string, which yields a rune
+func convertsCleanly(from, to reflect.Kind) bool {
+	switch {
+	case isInteger(from) && isInteger(to), isFloat(from) && isFloat(to):
+		return true
+	case from == reflect.Slice && to == reflect.String, from == reflect.String && to == reflect.Slice:
+		// []byte and string
+		return true
+	case to == reflect.Interface:
+		return true
+	}
+	return false
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// nullable is implemented by Null, so builders can bind its value
+type nullable interface {
+	nullValue() (any, bool)
+	valueType() reflect.Type
+}
+
+func (n Null[T]) nullValue() (any, bool)  { return n.V, n.Valid }
+func (n Null[T]) valueType() reflect.Type { return reflect.TypeFor[T]() }
+
+// nullableType is a wrapper type registered with RegisterNullable
+type nullableType struct {
+	elem  reflect.Type
+	value func(any) (any, bool)
+}
+
+var (
+	nullableTypes     sync.Map // reflect.Type to nullableType
+	hasNullableTypes  atomic.Bool
+	nullableInterface = reflect.TypeFor[nullable]()
+)
+
+// RegisterNullable registers W, an optional-value type such as a third-party Option[T],
+// so builders bind the value value returns, or NULL when it reports none, and CreateTable
+// maps W fields like T fields. Register before building queries with it; scanning into W
+// is up to W's own sql.Scanner.
+//
+//	pgstring.RegisterNullable(func(o option.Option[string]) (string, bool) { return o.Get() })
+func RegisterNullable[W, T any](value func(W) (T, bool)) {
+	nullableTypes.Store(reflect.TypeFor[W](), nullableType{
+		elem: reflect.TypeFor[T](),
+		value: func(w any) (any, bool) {
+			return value(w.(W))
+		},
+	})
+	hasNullableTypes.Store(true)
+}
+
+// bindable returns the value to bind for value: the value of a Null or registered
+// wrapper, or nil when it holds none; other values are returned as is
+func bindable(value any) any {
+	if n, ok := value.(nullable); ok && !isNilPointer(value) {
+		if v, valid := n.nullValue(); valid {
+			return v
+		}
+		return nil
+	}
+	if value == nil || !hasNullableTypes.Load() {
+		return value
+	}
+	if t, ok := nullableTypes.Load(reflect.TypeOf(value)); ok {
+		if v, valid := t.(nullableType).value(value); valid {
+			return v
+		}
+		return nil
+	}
+	return value
+}
+
+// nullableElem returns the type wrapped by a Null or registered wrapper type
+func nullableElem(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() != reflect.Ptr && typ.Implements(nullableInterface) {
+		return reflect.Zero(typ).Interface().(nullable).valueType(), true
+	}
+	if t, ok := nullableTypes.Load(typ); ok {
+		return t.(nullableType).elem, true
+	}
+	return nil, false
+}
@@ -0,0 +1,35 @@
+package pgstring
+
+import (
+	"maps"
+	"slices"
+)
+
+// NamedArg is a named argument and its value
+type NamedArg struct {
+	Name  string
+	Value any
+}
+
+// OrderedNamedArgs returns the args of the built query in the order their placeholders
+// first appear, followed by any args the SQL doesn't reference, sorted by name. Built SQL
+// and arg order don't depend on map iteration, so both are stable across runs, e.g. for
+// golden tests. It returns nil when the query has an error or its args are inlined.
+func (pg PgString) OrderedNamedArgs() []NamedArg {
+	sql, args, err := pg.Build()
+	if err != nil || len(args) == 0 {
+		return nil
+	}
+
+	ordered := make([]NamedArg, 0, len(args))
+	for _, name := range placeholders(sql) {
+		if value, ok := args[name]; ok {
+			ordered = append(ordered, NamedArg{Name: name, Value: value})
+			delete(args, name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		ordered = append(ordered, NamedArg{Name: name, Value: args[name]})
+	}
+	return ordered
+}
@@ -0,0 +1,280 @@
+package pgstring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// word is a keyword-like word outside parentheses, literals and comments
+type word struct {
+	text       string // upper-cased
+	start, end int
+}
+
+// topLevelWords returns the words of sql that aren't nested in parentheses, string literals,
+// quoted identifiers or comments, along with any top-level semicolons as ";" words
+func topLevelWords(sql string) []word {
+	var words []word
+	depth := 0
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"':
+			i = skipQuoted(sql, i, c)
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ';' && depth == 0:
+			words = append(words, word{text: ";", start: i, end: i + 1})
+		case isIdentStart(c) && (i == 0 || !isIdentChar(sql[i-1]) && sql[i-1] != '@' && sql[i-1] != '$'):
+			end := i + 1
+			for end < len(sql) && isIdentChar(sql[end]) {
+				end++
+			}
+			if depth == 0 {
+				words = append(words, word{text: strings.ToUpper(sql[i:end]), start: i, end: end})
+			}
+			i = end - 1
+		}
+	}
+	return words
+}
+
+// selectSections are the clauses ParseSelect understands, in the order they must appear.
+// LIMIT and OFFSET share a rank since Postgres accepts them in either order.
+var selectSections = []struct {
+	keyword []string
+	kind    clauseKind
+	rank    int
+}{
+	{[]string{"SELECT"}, clauseSelect, 0},
+	{[]string{"FROM"}, clauseFrom, 1},
+	{[]string{"WHERE"}, clauseWhere, 2},
+	{[]string{"GROUP", "BY"}, clauseGroupBy, 3},
+	{[]string{"HAVING"}, clauseHaving, 4},
+	{[]string{"ORDER", "BY"}, clauseOrderBy, 5},
+	{[]string{"LIMIT"}, clauseLimit, 6},
+	{[]string{"OFFSET"}, clauseOffset, 6},
+}
+
+// unsupportedWords are top-level keywords of statements too complex for ParseSelect
+var unsupportedWords = map[string]bool{
+	"UNION": true, "INTERSECT": true, "EXCEPT": true, "WINDOW": true,
+	"FETCH": true, "FOR": true, "INTO": true,
+}
+
+// joinWords are the words that can start a join in a FROM list
+var joinWords = map[string]bool{
+	"JOIN": true, "NATURAL": true, "INNER": true, "LEFT": true, "RIGHT": true,
+	"FULL": true, "CROSS": true, "OUTER": true,
+}
+
+// ParseSelect imports a simple SELECT statement into a builder, so legacy raw queries can
+// have clauses appended (extra filters, limits, tenant scoping) and be re-rendered. It
+// understands SELECT [DISTINCT], FROM with joins, WHERE, GROUP BY, HAVING, ORDER BY, LIMIT
+// and OFFSET; CTEs, set operations, locking clauses and comma-separated FROM lists are
+// rejected. Placeholders are kept; bind them with the methods that add clauses or args.
+//
+// Clauses added to the returned builder are placed in SELECT clause order, so a Where
+// added after parsing lands with the parsed conditions rather than after LIMIT.
+func ParseSelect(sql string) (PgString, error) {
+	sql = strings.TrimRight(strings.TrimSpace(sql), "; \t\n")
+	words := topLevelWords(sql)
+	if len(words) == 0 || words[0].text != "SELECT" || words[0].start != 0 {
+		return PgString{}, errors.New("ParseSelect: statement doesn't start with SELECT")
+	}
+
+	// Locate the clause keywords
+	type section struct {
+		kind        clauseKind
+		start, body int
+	}
+	var sections []section
+	rank := -1
+	seen := map[clauseKind]bool{}
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if w.text == ";" {
+			return PgString{}, errors.New("ParseSelect: multiple statements")
+		}
+		if unsupportedWords[w.text] {
+			return PgString{}, fmt.Errorf("ParseSelect: %s isn't supported", w.text)
+		}
+		for _, s := range selectSections {
+			if !matchWords(words[i:], s.keyword) {
+				continue
+			}
+			if seen[s.kind] || s.rank < rank {
+				return PgString{}, fmt.Errorf("ParseSelect: unexpected %s", strings.Join(s.keyword, " "))
+			}
+			seen[s.kind] = true
+			rank = s.rank
+			last := words[i+len(s.keyword)-1]
+			sections = append(sections, section{kind: s.kind, start: w.start, body: last.end})
+			i += len(s.keyword) - 1
+			break
+		}
+	}
+
+	pg := PgString{}
+	for i, s := range sections {
+		end := len(sql)
+		if i+1 < len(sections) {
+			end = sections[i+1].start
+		}
+		body := strings.TrimSpace(sql[s.body:end])
+		if body == "" {
+			return PgString{}, fmt.Errorf("ParseSelect: empty %s clause", clauseKeywords[s.kind])
+		}
+
+		switch s.kind {
+		case clauseSelect:
+			kind := clauseSelect
+			if first := topLevelWords(body); len(first) > 0 && first[0].start == 0 {
+				switch first[0].text {
+				case "DISTINCT":
+					kind = clauseSelectDistinct
+					body = strings.TrimSpace(body[first[0].end:])
+				case "ALL":
+					body = strings.TrimSpace(body[first[0].end:])
+				}
+			}
+			pg = pg.with(clause{kind: kind, sql: body})
+		case clauseFrom:
+			var err error
+			if pg, err = pg.parseFrom(body); err != nil {
+				return PgString{}, err
+			}
+		case clauseWhere:
+			// Conditions appended later are ANDed, so a top-level OR must be grouped
+			for _, w := range topLevelWords(body) {
+				if w.text == "OR" {
+					body = "(" + body + ")"
+					break
+				}
+			}
+			pg = pg.with(clause{kind: clauseWhere, sql: body})
+		default:
+			pg = pg.with(clause{kind: s.kind, sql: body})
+		}
+	}
+	pg.ordered = true
+	return pg, nil
+}
+
+// selectRanks orders the clauses of a SELECT for builders returned by ParseSelect
+var selectRanks = map[clauseKind]int{
+	clauseSelect:         0,
+	clauseSelectDistinct: 0,
+	clauseInto:           1,
+	clauseFrom:           2,
+	clauseTableSample:    3,
+	clauseJoin:           4,
+	clauseWhere:          5,
+	clauseGroupBy:        6,
+	clauseHaving:         7,
+	clauseOrderBy:        8,
+	clauseLimit:          9,
+	clauseOffset:         9,
+}
+
+// orderedPos returns where a clause of kind goes in SELECT clause order: after the clauses
+// ranked the same or lower. Kinds outside a SELECT have no position and are appended.
+func (pg PgString) orderedPos(kind clauseKind) (int, bool) {
+	rank, ok := selectRanks[kind]
+	if !ok {
+		return 0, false
+	}
+	for i, c := range pg.clauses {
+		if r, ok := selectRanks[c.kind]; ok && r > rank {
+			return i, true
+		}
+	}
+	return len(pg.clauses), true
+}
+
+// matchWords reports whether words starts with keyword
+func matchWords(words []word, keyword []string) bool {
+	if len(words) < len(keyword) {
+		return false
+	}
+	for i, k := range keyword {
+		if words[i].text != k {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFrom adds the FROM clause and joins of a parsed FROM list
+func (pg PgString) parseFrom(from string) (PgString, error) {
+	if strings.ContainsRune(topLevelText(from), ',') {
+		return pg, errors.New("ParseSelect: comma-separated FROM lists aren't supported, use JOIN")
+	}
+
+	// Each run of join words starts a new join
+	var starts []int
+	words := topLevelWords(from)
+	for i, w := range words {
+		if joinWords[w.text] && (i == 0 || !joinWords[words[i-1].text]) {
+			starts = append(starts, w.start)
+		}
+	}
+	if len(starts) == 0 {
+		return pg.From(from), nil
+	}
+	if starts[0] == 0 {
+		return pg, errors.New("ParseSelect: FROM starts with a join")
+	}
+
+	pg = pg.From(strings.TrimSpace(from[:starts[0]]))
+	for i, start := range starts {
+		end := len(from)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		join := strings.TrimSpace(from[start:end])
+		table := ""
+		for _, w := range topLevelWords(join) {
+			if w.text == "JOIN" {
+				table = join[w.end:]
+				break
+			}
+		}
+		pg = pg.with(clause{kind: clauseJoin, sql: join, table: tableName(table)})
+	}
+	return pg, nil
+}
+
+// topLevelText returns sql with everything nested in parentheses, literals or comments
+// removed, for checking top-level punctuation
+func topLevelText(sql string) string {
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"':
+			i = skipQuoted(sql, i, c)
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
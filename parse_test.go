@@ -0,0 +1,54 @@
+package pgstring_test
+
+import (
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestParseSelectRoundTrip(t *testing.T) {
+	sql := "SELECT DISTINCT u.id, count(o.id) FROM users u LEFT JOIN orders o ON o.user_id = u.id " +
+		"WHERE u.active GROUP BY u.id HAVING count(o.id) > 1 ORDER BY u.id LIMIT 10"
+	legacy, err := pgstring.ParseSelect(sql + ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSQL(t, legacy, sql, nil)
+}
+
+func TestParseSelectAppendsInClauseOrder(t *testing.T) {
+	legacy, err := pgstring.ParseSelect("SELECT id FROM users WHERE name = 'a;b' GROUP BY id LIMIT 10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSQL(t, legacy.Where("tenant_id = @tenant_id", map[string]any{"tenant_id": 3}).
+		Join("INNER", "teams t", "t.id = users.team_id").OrderBy("id"),
+		"SELECT id FROM users INNER JOIN teams t ON t.id = users.team_id "+
+			"WHERE (name = 'a;b') AND (tenant_id = @tenant_id) GROUP BY id ORDER BY id LIMIT 10",
+		map[string]any{"tenant_id": 3})
+}
+
+func TestParseSelectRejects(t *testing.T) {
+	for _, sql := range []string{
+		"UPDATE users SET a = 1",
+		"SELECT 1; SELECT 2",
+		"SELECT id FROM a UNION SELECT id FROM b",
+		"SELECT id FROM users FOR UPDATE",
+		"SELECT id FROM a, b",
+		"SELECT id FROM users LIMIT 1 WHERE id = 2",
+		"SELECT id FROM users WHERE",
+	} {
+		if _, err := pgstring.ParseSelect(sql); err == nil {
+			t.Errorf("ParseSelect(%q) succeeded", sql)
+		}
+	}
+}
+
+func TestParseSelectLimitReplaces(t *testing.T) {
+	legacy, err := pgstring.ParseSelect("SELECT id FROM users ORDER BY id LIMIT 10 OFFSET 20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSQL(t, legacy.Limit(5).Offset(40).OrderBy("name"),
+		"SELECT id FROM users ORDER BY id, name LIMIT 5 OFFSET 40", nil)
+}
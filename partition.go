@@ -0,0 +1,88 @@
+package pgstring
+
+import (
+	"fmt"
+	"time"
+)
+
+// PartitionPeriod is the time span covered by each partition of a range-partitioned table
+type PartitionPeriod string
+
+const (
+	Daily   PartitionPeriod = "day"
+	Weekly  PartitionPeriod = "week"
+	Monthly PartitionPeriod = "month"
+	Yearly  PartitionPeriod = "year"
+)
+
+// bounds returns the start of the period containing t and the start of the next one
+func (p PartitionPeriod) bounds(t time.Time) (time.Time, time.Time, error) {
+	y, m, d := t.Date()
+	switch p {
+	case Daily:
+		start := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 0, 1), nil
+	case Weekly:
+		// ISO weeks start on Monday
+		start := time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 0, 7), nil
+	case Monthly:
+		start := time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0), nil
+	case Yearly:
+		start := time.Date(y, 1, 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(1, 0, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown partition period %q", p)
+	}
+}
+
+// partitionName names the partition of parent starting at start, e.g. events_202406
+func (p PartitionPeriod) partitionName(parent string, start time.Time) string {
+	switch p {
+	case Monthly:
+		return parent + "_" + start.Format("200601")
+	case Yearly:
+		return parent + "_" + start.Format("2006")
+	default:
+		return parent + "_" + start.Format("20060102")
+	}
+}
+
+// CreatePartition creates the partition of the range-partitioned parent covering the period
+// that contains t, named after its start (events_20240601, events_202406 or events_2024)
+func CreatePartition(parent string, period PartitionPeriod, t time.Time) PgString {
+	start, end, err := period.bounds(t)
+	if err != nil {
+		return PgString{}.fail(err)
+	}
+
+	name := period.partitionName(parent, start)
+	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%s) TO (%s)",
+		name, parent, quoteLiteral(start.Format(time.DateOnly)), quoteLiteral(end.Format(time.DateOnly)))
+	return ddl(sql, name)
+}
+
+// DetachPartition adds a DETACH PARTITION action to an ALTER TABLE statement
+func (pg PgString) DetachPartition(partition string) PgString {
+	return pg.with(clause{kind: clauseAlterAction, sql: "DETACH PARTITION " + partition})
+}
+
+// RotatePartition returns the DDL of a periodic partition rotation: the partition covering
+// create is created if missing, and the one covering detach is detached from parent, e.g.
+// to be archived or dropped. A zero detach only creates. Bounds are dates, read in the
+// session time zone for timestamptz columns.
+//
+//	RotatePartition("events", Monthly, now.AddDate(0, 1, 0), now.AddDate(0, -12, 0))
+func RotatePartition(parent string, period PartitionPeriod, create, detach time.Time) Script {
+	script := NewScript(CreatePartition(parent, period, create))
+	if detach.IsZero() {
+		return script
+	}
+
+	start, _, err := period.bounds(detach)
+	if err != nil {
+		return script.Add(PgString{}.fail(err))
+	}
+	return script.Add(AlterTable(parent).DetachPartition(period.partitionName(parent, start)))
+}
@@ -0,0 +1,122 @@
+package pgcache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+// encodeRows encodes rows for the Store by db column rather than with the rows' own JSON
+// encoding, so fields hidden from encoding/json, e.g. tagged json:"-", round-trip too
+func encodeRows[T any](rows []T) ([]byte, error) {
+	encoded := make([]json.RawMessage, len(rows))
+	for i := range rows {
+		data, err := encodeValue(reflect.ValueOf(&rows[i]).Elem())
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = data
+	}
+	return json.Marshal(encoded)
+}
+
+// decodeRows decodes rows encoded by encodeRows
+func decodeRows[T any](data []byte) ([]T, error) {
+	var encoded []json.RawMessage
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, err
+	}
+	rows := make([]T, len(encoded))
+	for i, data := range encoded {
+		if err := decodeValue(data, reflect.ValueOf(&rows[i]).Elem()); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// encodeValue encodes a row: a struct as an object of its columns, recursing into nested
+// structs, and any other row, such as a scalar, as JSON
+func encodeValue(v reflect.Value) (json.RawMessage, error) {
+	if !isRow(v.Type()) {
+		return json.Marshal(v.Interface())
+	}
+	return encodeStruct(v)
+}
+
+// encodeStruct encodes a struct, or a pointer to one, as an object of its columns
+func encodeStruct(v reflect.Value) (json.RawMessage, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return json.RawMessage("null"), nil
+		}
+		v = v.Elem()
+	}
+
+	columns := make(map[string]json.RawMessage)
+	for _, field := range pgstring.StructFields(v.Type()) {
+		var data json.RawMessage
+		var err error
+		if field.Nested {
+			data, err = encodeStruct(v.Field(field.Index))
+		} else {
+			data, err = json.Marshal(v.Field(field.Index).Interface())
+		}
+		if err != nil {
+			return nil, err
+		}
+		columns[field.Column] = data
+	}
+	return json.Marshal(columns)
+}
+
+// decodeValue decodes a row encoded by encodeValue into the addressable value v
+func decodeValue(data json.RawMessage, v reflect.Value) error {
+	if !isRow(v.Type()) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+	return decodeStruct(data, v)
+}
+
+// decodeStruct decodes a struct encoded by encodeStruct into the addressable value v
+func decodeStruct(data json.RawMessage, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if string(data) == "null" {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	var columns map[string]json.RawMessage
+	if err := json.Unmarshal(data, &columns); err != nil {
+		return err
+	}
+	for _, field := range pgstring.StructFields(v.Type()) {
+		data, ok := columns[field.Column]
+		if !ok {
+			continue
+		}
+		var err error
+		if field.Nested {
+			err = decodeStruct(data, v.Field(field.Index))
+		} else {
+			err = json.Unmarshal(data, v.Field(field.Index).Addr().Interface())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+// isRow reports whether typ is scanned field by field from columns, like pgscan.Iterate
+// scans structs, rather than from a single column
+func isRow(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != reflect.TypeFor[time.Time]() && !reflect.PointerTo(typ).Implements(scannerType)
+}
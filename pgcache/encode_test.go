@@ -0,0 +1,54 @@
+package pgcache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type account struct {
+	ID           int        `db:"id"`
+	Email        string     `db:"email" json:"email_address"`
+	PasswordHash string     `db:"password_hash" json:"-"`
+	DeletedAt    *time.Time `db:"deleted_at"`
+	Owner        *owner     `db:"owner,nested"`
+}
+
+type owner struct {
+	ID    int    `db:"id"`
+	Token string `db:"token" json:"-"`
+}
+
+func TestRowsRoundTrip(t *testing.T) {
+	deleted := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := []account{
+		{ID: 1, Email: "a@example.com", PasswordHash: "secret", DeletedAt: &deleted, Owner: &owner{ID: 7, Token: "t"}},
+		{ID: 2, Email: "b@example.com", PasswordHash: "hidden"},
+	}
+	data, err := encodeRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeRows[account](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, rows) {
+		t.Errorf("got  %+v\nwant %+v", decoded, rows)
+	}
+}
+
+func TestScalarRowsRoundTrip(t *testing.T) {
+	rows := []time.Time{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	data, err := encodeRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeRows[time.Time](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, rows) {
+		t.Errorf("got %v, want %v", decoded, rows)
+	}
+}
@@ -0,0 +1,72 @@
+package pgcache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	// byTable indexes the keys of the entries read from each table
+	byTable map[string]map[string]struct{}
+}
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]entry), byTable: make(map[string]map[string]struct{})}
+}
+
+// Get returns the value stored under key, if any and not expired
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(e.expires) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set stores value under key for ttl, recording the tables it was read from
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tables []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = entry{value: value, expires: time.Now().Add(ttl)}
+	for _, table := range tables {
+		keys := s.byTable[table]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			s.byTable[table] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+// Invalidate removes every value read from any of tables
+func (s *MemoryStore) Invalidate(ctx context.Context, tables ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, table := range tables {
+		for key := range s.byTable[table] {
+			delete(s.entries, key)
+		}
+		delete(s.byTable, table)
+	}
+	return nil
+}
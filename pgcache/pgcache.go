@@ -0,0 +1,90 @@
+// Package pgcache serves SELECT results of pgstring queries from a pluggable cache and
+// invalidates them by table when writes are executed through it.
+package pgcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oliverpaddock/pgstring"
+	"github.com/oliverpaddock/pgstring/pgexec"
+	"github.com/oliverpaddock/pgstring/pgscan"
+)
+
+// Store is a cache backend, such as MemoryStore or a Redis adapter
+type Store interface {
+	// Get returns the value stored under key, if any and not expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl, recording the tables it was read from
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tables []string) error
+	// Invalidate removes every value read from any of tables
+	Invalidate(ctx context.Context, tables ...string) error
+}
+
+// Cache caches query results in a Store
+type Cache struct {
+	store Store
+	ttl   time.Duration
+}
+
+// WithCache creates a Cache storing results in store for ttl. Writes made outside the
+// Cache aren't seen, so ttl bounds how stale results can get.
+func WithCache(store Store, ttl time.Duration) *Cache {
+	return &Cache{store: store, ttl: ttl}
+}
+
+// Query returns the rows of pg scanned into T (see pgscan.Iterate), serving them from the
+// cache when present. Statements that write tables are run without caching. Struct rows
+// are cached by db column, so they come back as scanned whatever their JSON tags.
+func Query[T any](ctx context.Context, c *Cache, db pgexec.Querier, pg pgstring.PgString) ([]T, error) {
+	read, written := pg.Tables()
+	cacheable := len(read) > 0 && len(written) == 0 && pg.Err() == nil
+	key := ""
+	if cacheable {
+		key = pg.CacheKey()
+		if data, ok, err := c.store.Get(ctx, key); err != nil {
+			return nil, err
+		} else if ok {
+			if cached, err := decodeRows[T](data); err == nil {
+				return cached, nil
+			}
+		}
+	}
+
+	results := []T{}
+	for row, err := range pgscan.Iterate[T](ctx, db, pg) {
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, row)
+	}
+
+	if cacheable {
+		data, err := encodeRows(results)
+		if err == nil {
+			err = c.store.Set(ctx, key, data, c.ttl, read)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// Exec executes pg and invalidates the cached results of the tables it writes
+func (c *Cache) Exec(ctx context.Context, db pgexec.Querier, pg pgstring.PgString) (pgconn.CommandTag, error) {
+	tag, err := pgexec.Exec(ctx, db, pg)
+	if err != nil {
+		return tag, err
+	}
+	if _, written := pg.Tables(); len(written) > 0 {
+		return tag, c.store.Invalidate(ctx, written...)
+	}
+	return tag, nil
+}
+
+// Invalidate removes the cached results read from tables, e.g. after writes made elsewhere
+func (c *Cache) Invalidate(ctx context.Context, tables ...string) error {
+	return c.store.Invalidate(ctx, tables...)
+}
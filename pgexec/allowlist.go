@@ -0,0 +1,87 @@
+package pgexec
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+// AllowList is a registry of the query fingerprints (see PgString.Fingerprint) a service
+// is permitted to run
+type AllowList struct {
+	mu           sync.RWMutex
+	fingerprints map[string]bool
+}
+
+// NewAllowList creates an AllowList permitting queries
+func NewAllowList(queries ...pgstring.PgString) (*AllowList, error) {
+	a := &AllowList{fingerprints: map[string]bool{}}
+	if err := a.Register(queries...); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Register permits queries. Fingerprints depend on the shape of the SQL, so register one
+// query per variant, e.g. per optional filter or bulk row count.
+func (a *AllowList) Register(queries ...pgstring.PgString) error {
+	for _, pg := range queries {
+		if err := pg.Err(); err != nil {
+			return err
+		}
+	}
+	fingerprints := make([]string, len(queries))
+	for i, pg := range queries {
+		fingerprints[i] = pg.Fingerprint()
+	}
+	a.RegisterFingerprints(fingerprints...)
+	return nil
+}
+
+// RegisterFingerprints permits queries by fingerprint, e.g. from a generated list
+func (a *AllowList) RegisterFingerprints(fingerprints ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, fp := range fingerprints {
+		a.fingerprints[fp] = true
+	}
+}
+
+// Allowed reports whether pg's fingerprint is registered
+func (a *AllowList) Allowed(pg pgstring.PgString) bool {
+	fp := pg.Fingerprint()
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.fingerprints[fp]
+}
+
+// ErrNotAllowed is returned for a query whose fingerprint isn't in the enforced AllowList
+type ErrNotAllowed struct {
+	Fingerprint string
+	SQL         string
+}
+
+func (e ErrNotAllowed) Error() string {
+	return fmt.Sprintf("query %s is not in the allow list: %s", e.Fingerprint, e.SQL)
+}
+
+// enforced holds the AllowList registered with Enforce
+var enforced atomic.Pointer[AllowList]
+
+// Enforce makes Exec, Query, QueryRow and the helpers built on them reject queries that
+// aren't in a with ErrNotAllowed; nil disables enforcement. Queries run by the migration
+// runner are checked too, so enforce after migrating or register them.
+func Enforce(a *AllowList) {
+	enforced.Store(a)
+}
+
+// checkAllowed returns ErrNotAllowed when an AllowList is enforced and pg isn't in it
+func checkAllowed(pg pgstring.PgString) error {
+	a := enforced.Load()
+	if a == nil || a.Allowed(pg) {
+		return nil
+	}
+	return ErrNotAllowed{Fingerprint: pg.Fingerprint(), SQL: pg.String()}
+}
@@ -0,0 +1,65 @@
+package pgexec
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oliverpaddock/pgstring"
+)
+
+// ExecBulkChunked inserts rows, a slice of structs whose type is registered with
+// pgstring.Register or implements pgstring.Tabler, as ValuesBulk statements of at most
+// chunkSize rows each, further split by Chunks to stay within pgstring.MaxParams. A
+// chunkSize of 0 only splits at the parameter limit. The statements run in one transaction
+// (a savepoint when db already is one), so a failing chunk rolls back the chunks before
+// it. It returns the number of rows inserted.
+//
+//	n, err := pgexec.ExecBulkChunked(ctx, pool, events, 1000, pgstring.BulkSkipConflicts)
+func ExecBulkChunked(ctx context.Context, db DB, rows any, chunkSize int, options ...pgstring.BulkOption) (int64, error) {
+	val := reflect.ValueOf(rows)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("pgexec: ExecBulkChunked needs a slice of structs, not %T", rows)
+	}
+	if val.Len() == 0 {
+		return 0, nil
+	}
+	if chunkSize <= 0 {
+		chunkSize = val.Len()
+	}
+
+	elemType := val.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	model := reflect.Zero(elemType).Interface()
+	table, ok := pgstring.TableOf(model)
+	if !ok {
+		return 0, fmt.Errorf("pgexec: %s has no table, register it or implement pgstring.Tabler", elemType)
+	}
+	base := pgstring.InsertInto(table).Obj(model)
+
+	var inserted int64
+	err := pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		for start := 0; start < val.Len(); start += chunkSize {
+			end := min(start+chunkSize, val.Len())
+			statement := base.ValuesBulk(val.Slice(start, end).Interface(), options...)
+			for _, chunk := range statement.Chunks() {
+				tag, err := Exec(ctx, tx, chunk)
+				if err != nil {
+					return fmt.Errorf("pgexec: rows %d to %d: %w", start, end-1, err)
+				}
+				inserted += tag.RowsAffected()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
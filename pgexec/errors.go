@@ -0,0 +1,68 @@
+package pgexec
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrUniqueViolation is returned when a statement violates a unique constraint (23505)
+type ErrUniqueViolation struct {
+	Constraint string
+	Table      string
+	Err        *pgconn.PgError
+}
+
+func (e ErrUniqueViolation) Error() string { return e.Err.Error() }
+func (e ErrUniqueViolation) Unwrap() error { return e.Err }
+
+// ErrForeignKeyViolation is returned when a statement violates a foreign key (23503)
+type ErrForeignKeyViolation struct {
+	Constraint string
+	Table      string
+	Err        *pgconn.PgError
+}
+
+func (e ErrForeignKeyViolation) Error() string { return e.Err.Error() }
+func (e ErrForeignKeyViolation) Unwrap() error { return e.Err }
+
+// ErrCheckViolation is returned when a statement violates a CHECK constraint (23514)
+type ErrCheckViolation struct {
+	Constraint string
+	Table      string
+	Err        *pgconn.PgError
+}
+
+func (e ErrCheckViolation) Error() string { return e.Err.Error() }
+func (e ErrCheckViolation) Unwrap() error { return e.Err }
+
+// Translate converts constraint violations reported by Postgres into ErrUniqueViolation,
+// ErrForeignKeyViolation and ErrCheckViolation, so callers can branch with errors.As.
+// Other errors are returned unchanged. Exec and QueryRow translate their errors already;
+// use Translate for errors returned by rows from Query.
+func Translate(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case "23505":
+		return ErrUniqueViolation{Constraint: pgErr.ConstraintName, Table: pgErr.TableName, Err: pgErr}
+	case "23503":
+		return ErrForeignKeyViolation{Constraint: pgErr.ConstraintName, Table: pgErr.TableName, Err: pgErr}
+	case "23514":
+		return ErrCheckViolation{Constraint: pgErr.ConstraintName, Table: pgErr.TableName, Err: pgErr}
+	}
+	return err
+}
+
+// translatedRow translates the errors of a pgx.Row
+type translatedRow struct {
+	row pgx.Row
+}
+
+func (r translatedRow) Scan(dest ...any) error {
+	return Translate(r.row.Scan(dest...))
+}
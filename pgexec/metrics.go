@@ -0,0 +1,112 @@
+package pgexec
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oliverpaddock/pgstring"
+)
+
+// Metrics observes the queries run through this package, labeled by statement kind (the
+// builder's Operation) and main table. See pgprom for a Prometheus adapter.
+type Metrics interface {
+	// ObserveBuild reports how long rendering the query took, and its build error if any
+	ObserveBuild(duration time.Duration, statementKind, table string, err error)
+	// ObserveExec reports how long the database took to execute the query. Query is
+	// observed when its rows are returned, not when they are consumed; pgx.ErrNoRows is
+	// not reported as an error.
+	ObserveExec(duration time.Duration, statementKind, table string, err error)
+}
+
+// metricsHolder wraps the registered Metrics so the atomic pointer has a single type
+type metricsHolder struct {
+	m Metrics
+}
+
+// metrics holds the Metrics registered with SetMetrics
+var metrics atomic.Pointer[metricsHolder]
+
+// SetMetrics registers m to observe every Exec, Query and QueryRow; nil disables metrics
+func SetMetrics(m Metrics) {
+	if m == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&metricsHolder{m: m})
+}
+
+// currentMetrics returns the registered Metrics, or nil
+func currentMetrics() Metrics {
+	if h := metrics.Load(); h != nil {
+		return h.m
+	}
+	return nil
+}
+
+// labels returns the statement kind and main table of pg: the first table written, or
+// the first read when it writes none
+func labels(pg pgstring.PgString) (string, string) {
+	read, written := pg.Tables()
+	switch {
+	case len(written) > 0:
+		return pg.Operation(), written[0]
+	case len(read) > 0:
+		return pg.Operation(), read[0]
+	default:
+		return pg.Operation(), ""
+	}
+}
+
+// build builds pg, reporting it to the registered Metrics, and checks it against the
+// enforced AllowList and its ReadOnly option
+func build(pg pgstring.PgString) (string, map[string]any, error) {
+	var sql string
+	var namedArgs map[string]any
+	var err error
+	if m := currentMetrics(); m == nil {
+		sql, namedArgs, err = pg.Build()
+	} else {
+		start := time.Now()
+		sql, namedArgs, err = pg.Build()
+		kind, table := labels(pg)
+		m.ObserveBuild(time.Since(start), kind, table, err)
+	}
+
+	if err == nil {
+		err = checkAllowed(pg)
+	}
+	if err == nil {
+		err = checkReadOnly(pg)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, namedArgs, nil
+}
+
+// observeExec reports an execution of pg that took elapsed to the registered Metrics
+func observeExec(pg pgstring.PgString, elapsed time.Duration, err error) {
+	m := currentMetrics()
+	if m == nil {
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	kind, table := labels(pg)
+	m.ObserveExec(elapsed, kind, table, err)
+}
+
+// observedRow calls done with the result of its query when scanned
+type observedRow struct {
+	row  pgx.Row
+	done func(err error)
+}
+
+func (r observedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	r.done(err)
+	return err
+}
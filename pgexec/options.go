@@ -0,0 +1,88 @@
+package pgexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oliverpaddock/pgstring"
+)
+
+// ErrTooManyRows is returned by the rows of a query that returned more than its MaxRows
+var ErrTooManyRows = errors.New("query returned more rows than its MaxRows")
+
+// ErrReadOnly is returned when a statement marked ReadOnly would write
+type ErrReadOnly struct {
+	Kind pgstring.StatementKind
+}
+
+func (e ErrReadOnly) Error() string {
+	return fmt.Sprintf("read-only statement is a %s", e.Kind)
+}
+
+// checkReadOnly returns ErrReadOnly when pg is marked ReadOnly but is built to write. Raw
+// SQL is taken at its word.
+func checkReadOnly(pg pgstring.PgString) error {
+	if !pg.ExecOptions().ReadOnly {
+		return nil
+	}
+	switch kind := pg.Kind(); kind {
+	case pgstring.KindInsert, pgstring.KindUpdate, pgstring.KindDelete, pgstring.KindDDL:
+		return ErrReadOnly{Kind: kind}
+	}
+	return nil
+}
+
+// withTimeout returns ctx bounded by pg's Timeout, if it has one
+func withTimeout(ctx context.Context, pg pgstring.PgString) (context.Context, context.CancelFunc) {
+	if timeout := pg.ExecOptions().Timeout; timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {}
+}
+
+// optionRows applies a query's MaxRows to its rows, and releases its timeout when closed
+type optionRows struct {
+	pgx.Rows
+	maxRows int
+	n       int
+	cancel  context.CancelFunc
+	err     error
+}
+
+func (r *optionRows) Next() bool {
+	if r.err != nil || !r.Rows.Next() {
+		return false
+	}
+	r.n++
+	if r.maxRows > 0 && r.n > r.maxRows {
+		r.err = ErrTooManyRows
+		r.Rows.Close()
+		return false
+	}
+	return true
+}
+
+func (r *optionRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.Rows.Err()
+}
+
+func (r *optionRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// cancelRow releases a query's timeout once its row is scanned
+type cancelRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r cancelRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
@@ -0,0 +1,310 @@
+// Package pgexec executes PgString queries with pgx.
+package pgexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oliverpaddock/pgstring"
+)
+
+// Querier is implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// DB is a Querier that can start transactions
+type DB interface {
+	Querier
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// ErrInTransaction is returned when a statement marked NonTransactional is executed in a transaction
+var ErrInTransaction = errors.New("statement can't run inside a transaction")
+
+// args converts named args into pgx query arguments. Queries without args are sent
+// without any, which lets pgx use the simple protocol for multi-statement DDL.
+func args(namedArgs map[string]any) []any {
+	if len(namedArgs) == 0 {
+		return nil
+	}
+	return []any{pgx.NamedArgs(namedArgs)}
+}
+
+// Exec builds pg and executes it, honoring its ExecOptions. Statements marked
+// NonTransactional fail with ErrInTransaction when db is a pgx.Tx.
+//
+// A LockTimeout is applied with SET LOCAL, in a transaction started for the statement
+// unless db already is one. An IdempotencyKey is claimed in IdempotencyTable in that same
+// transaction; when the key was already applied the statement is skipped and an empty
+// CommandTag is returned without an error. Retryable statements are retried with backoff on serialization
+// failures and deadlocks, except inside a transaction, which the failure has aborted.
+// Constraint violations are returned as the typed errors of Translate. Unchanged updates
+// (see SetDiff) are skipped. A Timeout bounds the whole execution, retries included.
+func Exec(ctx context.Context, db Querier, pg pgstring.PgString) (pgconn.CommandTag, error) {
+	if pg.Unchanged() {
+		return pgconn.CommandTag{}, nil
+	}
+	sql, namedArgs, err := build(pg)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	_, inTx := db.(pgx.Tx)
+	if inTx && pg.IsNonTransactional() {
+		return pgconn.CommandTag{}, ErrInTransaction
+	}
+
+	opts := pg.ExecOptions()
+	if opts.LockTimeout > 0 && pg.IsNonTransactional() {
+		return pgconn.CommandTag{}, errors.New("lock timeout can't be applied to a non-transactional statement")
+	}
+	if opts.IdempotencyKey != "" && pg.IsNonTransactional() {
+		return pgconn.CommandTag{}, errors.New("idempotency key can't be applied to a non-transactional statement")
+	}
+
+	execCtx, cancel := withTimeout(ctx, pg)
+	defer cancel()
+	exec := func() (pgconn.CommandTag, error) {
+		if opts.LockTimeout == 0 && opts.IdempotencyKey == "" {
+			return db.Exec(execCtx, sql, args(namedArgs)...)
+		}
+		return execInTx(execCtx, db, opts, sql, args(namedArgs))
+	}
+	start := time.Now()
+	var tag pgconn.CommandTag
+	if inTx || !opts.ShouldRetry() {
+		tag, err = exec()
+	} else {
+		tag, err = retry(execCtx, exec)
+	}
+	elapsed := time.Since(start)
+	err = Translate(err)
+	observeExec(pg, elapsed, err)
+	reportSlow(ctx, db, pg, sql, namedArgs, elapsed, err)
+	return tag, err
+}
+
+// execInTx executes sql in a transaction, db's own or one started for it, after applying
+// the LockTimeout and claiming the IdempotencyKey of opts
+func execInTx(ctx context.Context, db Querier, opts pgstring.ExecOptions, sql string, arguments []any) (pgconn.CommandTag, error) {
+	run := func(tx pgx.Tx) (pgconn.CommandTag, error) {
+		skip, err := prepareTx(ctx, tx, opts)
+		if err != nil || skip {
+			return pgconn.CommandTag{}, err
+		}
+		return tx.Exec(ctx, sql, arguments...)
+	}
+	if tx, ok := db.(pgx.Tx); ok {
+		return run(tx)
+	}
+
+	beginner, ok := db.(DB)
+	if !ok {
+		return pgconn.CommandTag{}, errors.New("lock timeout or idempotency key requires a db that can begin transactions")
+	}
+
+	var tag pgconn.CommandTag
+	err := pgx.BeginFunc(ctx, beginner, func(tx pgx.Tx) error {
+		var err error
+		tag, err = run(tx)
+		return err
+	})
+	return tag, err
+}
+
+// prepareTx applies the LockTimeout of opts to tx and claims its IdempotencyKey, reporting
+// whether the statement must be skipped because the key was already applied
+func prepareTx(ctx context.Context, tx Querier, opts pgstring.ExecOptions) (bool, error) {
+	if opts.LockTimeout > 0 {
+		if err := setLockTimeout(ctx, tx, opts.LockTimeout); err != nil {
+			return false, err
+		}
+	}
+	if opts.IdempotencyKey == "" {
+		return false, nil
+	}
+	claimed, err := claimKey(ctx, tx, opts.IdempotencyKey)
+	return !claimed, err
+}
+
+// claimKey records key in IdempotencyTable, reporting false when it is already there. The
+// claim is part of the current transaction, so it is undone if the statement fails.
+func claimKey(ctx context.Context, tx Querier, key string) (bool, error) {
+	tag, err := tx.Exec(ctx, "INSERT INTO "+pgstring.IdempotencyTable+" (key) VALUES ($1) ON CONFLICT DO NOTHING", key)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// setLockTimeout sets lock_timeout for the rest of the current transaction
+func setLockTimeout(ctx context.Context, db Querier, timeout time.Duration) error {
+	_, err := db.Exec(ctx, fmt.Sprintf("SET LOCAL lock_timeout = %d", max(timeout.Milliseconds(), 1)))
+	return err
+}
+
+// ExecScript executes the statements of s in order, stopping at the first error. A script
+// with a search_path (see Script.WithSearchPath) runs in a transaction that sets it with
+// SET LOCAL, so it applies to the script's statements on a single connection and isn't
+// left on pooled connections; when db is already a transaction, the setting lasts until it ends.
+func ExecScript(ctx context.Context, db Querier, s pgstring.Script) error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if schemas := s.SearchPath(); len(schemas) > 0 {
+		return execScriptInSearchPath(ctx, db, s, schemas)
+	}
+	return execStatements(ctx, db, s)
+}
+
+// execStatements executes the statements of s in order, stopping at the first error
+func execStatements(ctx context.Context, db Querier, s pgstring.Script) error {
+	for _, statement := range s.Statements() {
+		if _, err := Exec(ctx, db, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execScriptInSearchPath executes s in a transaction with search_path set to schemas
+func execScriptInSearchPath(ctx context.Context, db Querier, s pgstring.Script, schemas []string) error {
+	for _, statement := range s.Statements() {
+		if statement.IsNonTransactional() {
+			return errors.New("a script with a search_path can't contain non-transactional statements")
+		}
+	}
+
+	run := func(tx Querier) error {
+		if _, err := Exec(ctx, tx, pgstring.SearchPathLocal(schemas...)); err != nil {
+			return err
+		}
+		return execStatements(ctx, tx, s)
+	}
+	if tx, ok := db.(pgx.Tx); ok {
+		return run(tx)
+	}
+	beginner, ok := db.(DB)
+	if !ok {
+		return errors.New("a script with a search_path requires a db that can begin transactions")
+	}
+	return pgx.BeginFunc(ctx, beginner, func(tx pgx.Tx) error { return run(tx) })
+}
+
+// Query builds pg and runs it, returning the result rows. A LockTimeout is only applied
+// when db is a transaction, and an IdempotencyKey requires one: an already applied key
+// returns no rows without running, like an unchanged update. A Timeout
+// lasts until the rows are closed, and reading more than MaxRows rows stops with
+// ErrTooManyRows from the rows' Err.
+func Query(ctx context.Context, db Querier, pg pgstring.PgString) (pgx.Rows, error) {
+	if pg.Unchanged() {
+		return emptyRows{}, nil
+	}
+	sql, namedArgs, err := build(pg)
+	if err != nil {
+		return nil, err
+	}
+	if skip, err := prepareQuery(ctx, db, pg); err != nil || skip {
+		if err != nil {
+			return nil, err
+		}
+		return emptyRows{}, nil
+	}
+	queryCtx, cancel := withTimeout(ctx, pg)
+	start := time.Now()
+	rows, err := db.Query(queryCtx, sql, args(namedArgs)...)
+	elapsed := time.Since(start)
+	err = Translate(err)
+	observeExec(pg, elapsed, err)
+	if err != nil {
+		cancel()
+		reportSlow(ctx, db, pg, sql, namedArgs, elapsed, err)
+		return nil, err
+	}
+	if isSlow(pg, elapsed) {
+		// The plan is fetched once the rows are closed, since the connection is busy until then
+		rows = &slowRows{Rows: rows, report: func() { reportSlow(ctx, db, pg, sql, namedArgs, elapsed, nil) }}
+	}
+	if opts := pg.ExecOptions(); opts.Timeout > 0 || opts.MaxRows > 0 {
+		rows = &optionRows{Rows: rows, maxRows: opts.MaxRows, cancel: cancel}
+	}
+	return rows, nil
+}
+
+// QueryRow builds pg and runs it, returning at most one row. Build errors are
+// reported by the row's Scan; unchanged updates and already applied IdempotencyKeys
+// report pgx.ErrNoRows without running. A
+// Timeout lasts until the row is scanned.
+func QueryRow(ctx context.Context, db Querier, pg pgstring.PgString) pgx.Row {
+	if pg.Unchanged() {
+		return errRow{err: pgx.ErrNoRows}
+	}
+	sql, namedArgs, err := build(pg)
+	if err != nil {
+		return errRow{err: err}
+	}
+	if skip, err := prepareQuery(ctx, db, pg); err != nil {
+		return errRow{err: err}
+	} else if skip {
+		return errRow{err: pgx.ErrNoRows}
+	}
+	queryCtx, cancel := withTimeout(ctx, pg)
+	start := time.Now()
+	var row pgx.Row = translatedRow{row: db.QueryRow(queryCtx, sql, args(namedArgs)...)}
+	if pg.ExecOptions().Timeout > 0 {
+		row = cancelRow{row: row, cancel: cancel}
+	}
+	if currentMetrics() == nil && pg.ExecOptions().OnSlow == nil {
+		return row
+	}
+	return observedRow{row: row, done: func(err error) {
+		elapsed := time.Since(start)
+		observeExec(pg, elapsed, err)
+		reportSlow(ctx, db, pg, sql, namedArgs, elapsed, err)
+	}}
+}
+
+// prepareQuery applies pg's LockTimeout and claims its IdempotencyKey when db is a
+// transaction, reporting whether the query must be skipped. Queries outside a transaction
+// run without a lock timeout, since their rows outlive any transaction started here, and
+// can't claim a key.
+func prepareQuery(ctx context.Context, db Querier, pg pgstring.PgString) (bool, error) {
+	opts := pg.ExecOptions()
+	tx, ok := db.(pgx.Tx)
+	if !ok {
+		if opts.IdempotencyKey != "" {
+			return false, errors.New("a query with an idempotency key must run in a transaction")
+		}
+		return false, nil
+	}
+	return prepareTx(ctx, tx, opts)
+}
+
+// errRow is a pgx.Row that fails with err
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...any) error {
+	return r.err
+}
+
+// emptyRows is a pgx.Rows without rows
+type emptyRows struct{}
+
+func (emptyRows) Close()                                       {}
+func (emptyRows) Err() error                                   { return nil }
+func (emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (emptyRows) Next() bool                                   { return false }
+func (emptyRows) Scan(dest ...any) error                       { return pgx.ErrNoRows }
+func (emptyRows) Values() ([]any, error)                       { return nil, pgx.ErrNoRows }
+func (emptyRows) RawValues() [][]byte                          { return nil }
+func (emptyRows) Conn() *pgx.Conn                              { return nil }
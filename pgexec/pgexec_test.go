@@ -0,0 +1,60 @@
+package pgexec_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oliverpaddock/pgstring"
+	"github.com/oliverpaddock/pgstring/pgexec"
+)
+
+// fakeTx is a transaction that records executed statements and keeps an in-memory
+// idempotency table
+type fakeTx struct {
+	pgx.Tx
+	executed []string
+	keys     map[string]bool
+}
+
+func (tx *fakeTx) Exec(_ context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	if strings.HasPrefix(sql, "INSERT INTO "+pgstring.IdempotencyTable) {
+		key := arguments[0].(string)
+		if tx.keys[key] {
+			return pgconn.NewCommandTag("INSERT 0 0"), nil
+		}
+		tx.keys[key] = true
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	}
+	tx.executed = append(tx.executed, sql)
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func TestExecIdempotencyKey(t *testing.T) {
+	tx := &fakeTx{keys: map[string]bool{}}
+	update := pgstring.Update("accounts").Set(map[string]any{"balance": 0}).Where("id = 1").IdempotencyKey("req-1")
+
+	tag, err := pgexec.Exec(context.Background(), tx, update)
+	if err != nil || tag.RowsAffected() != 1 {
+		t.Fatalf("first Exec = %v, %v", tag, err)
+	}
+	tag, err = pgexec.Exec(context.Background(), tx, update)
+	if err != nil || tag.RowsAffected() != 0 {
+		t.Fatalf("repeated Exec = %v, %v", tag, err)
+	}
+	if len(tx.executed) != 1 {
+		t.Errorf("statement executed %d times, want once", len(tx.executed))
+	}
+}
+
+func TestExecIdempotencyKeyNeedsTransaction(t *testing.T) {
+	update := pgstring.Update("accounts").Set(map[string]any{"balance": 0}).Where("id = 1").IdempotencyKey("req-1")
+	if _, err := pgexec.Exec(context.Background(), fakeDB{}, update); err == nil {
+		t.Error("expected an error from a db that can't begin transactions")
+	}
+	if _, err := pgexec.Query(context.Background(), fakeDB{}, update.Returning("id")); err == nil {
+		t.Error("expected an error from a keyed query outside a transaction")
+	}
+}
@@ -0,0 +1,74 @@
+package pgexec
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// retryAttempts is how many times a retryable statement is tried in total
+	retryAttempts = 5
+	// retryBaseDelay is the delay before the first retry, doubled for each further one
+	retryBaseDelay = 20 * time.Millisecond
+)
+
+// isTransient reports whether err is a serialization failure (40001) or deadlock (40P01),
+// which succeed when the work is retried
+func isTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// retry calls fn until it succeeds, fails with a non-transient error or runs out of attempts
+func retry[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := fn()
+		if err == nil || !isTransient(err) || attempt == retryAttempts-1 {
+			return result, err
+		}
+		if err := sleep(ctx, backoff(attempt)); err != nil {
+			return result, err
+		}
+	}
+}
+
+// backoff returns the jittered delay before retry attempt+1
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << attempt
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WithSerializableRetry runs fn in a SERIALIZABLE transaction and commits it, retrying the
+// whole transaction with backoff when it fails with a serialization failure or deadlock.
+// fn may run several times, so it must not have side effects outside the transaction.
+func WithSerializableRetry(ctx context.Context, db DB, fn func(tx Querier) error) error {
+	_, err := retry(ctx, func() (struct{}, error) {
+		return struct{}{}, pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE"); err != nil {
+				return err
+			}
+			return fn(tx)
+		})
+	})
+	return err
+}
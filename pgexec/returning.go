@@ -0,0 +1,339 @@
+package pgexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oliverpaddock/pgstring"
+)
+
+// ScanReturning runs a ValuesBulk insert with a RETURNING clause and scans the returned
+// rows back into the elements of rows, the slice of structs (or struct pointers) the
+// statement was built from, so generated IDs and defaults land on the source values:
+//
+//	q := pgstring.InsertInto("users").Obj(User{}).ValuesBulk(users).Returning([]string{"id", "email"})
+//	err := pgexec.ScanReturning(ctx, pool, q, users)
+//
+// Postgres doesn't guarantee the order of returned rows, so they are matched to the
+// elements by the returned columns every element has a value for, like a unique email;
+// RETURNING must include one. Returned columns are matched to fields by name. Getting a
+// row that matches no element, or fewer rows than elements, is an error.
+func ScanReturning(ctx context.Context, db Querier, pg pgstring.PgString, rows any) error {
+	val := reflect.ValueOf(rows)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Slice {
+		return fmt.Errorf("pgexec: ScanReturning needs a slice of structs, not %T", rows)
+	}
+
+	result, err := Query(ctx, db, pg)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	matcher, err := newRowMatcher(val, result.FieldDescriptions())
+	if err != nil {
+		return err
+	}
+	n := 0
+	for result.Next() {
+		returned, err := matcher.scan(result)
+		if err != nil {
+			return err
+		}
+		i, ok := matcher.match(returned)
+		if !ok {
+			return fmt.Errorf("pgexec: returned row %d matches no element", n)
+		}
+		for _, field := range matcher.fields {
+			reflect.Indirect(val.Index(i)).Field(field.Index).Set(returned.Field(field.Index))
+		}
+		n++
+	}
+	if err := result.Err(); err != nil {
+		return err
+	}
+	if n != val.Len() {
+		return fmt.Errorf("pgexec: RETURNING returned %d rows for %d elements", n, val.Len())
+	}
+	return nil
+}
+
+// returningPointers returns pointers to the fields of elem for the returned columns
+func returningPointers(elem reflect.Value, descriptions []pgconn.FieldDescription) ([]any, error) {
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return nil, fmt.Errorf("pgexec: nil %s element", elem.Type())
+		}
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("pgexec: ScanReturning needs a slice of structs, not of %s", elem.Type())
+	}
+
+	fields := pgstring.StructFields(elem.Type())
+	dest := make([]any, len(descriptions))
+	for i, d := range descriptions {
+		for _, field := range fields {
+			if !field.Nested && strings.EqualFold(field.Column, d.Name) {
+				dest[i] = field.Pointer(elem)
+				break
+			}
+		}
+		if dest[i] == nil {
+			return nil, fmt.Errorf("pgexec: returned column %s has no field in %s", d.Name, elem.Type())
+		}
+	}
+	return dest, nil
+}
+
+// BulkResult reports which elements of a bulk insert were inserted and which were skipped
+// as conflicts, by index into the source slice
+type BulkResult struct {
+	Inserted []int
+	Skipped  []int
+}
+
+// InsertBulk runs a ValuesBulk insert built with BulkSkipConflicts and a RETURNING clause,
+// reporting which elements of rows, the slice the statement was built from, were inserted.
+// Returned rows are matched to the elements by the returned columns every element has a
+// value for, so RETURNING must include an identifying column such as the conflict key;
+// other returned columns, like generated IDs, are scanned into the inserted elements:
+//
+//	q := pgstring.InsertInto("users").Obj(User{}).ValuesBulk(users, pgstring.BulkSkipConflicts).
+//		Returning([]string{"id", "email"})
+//	result, err := pgexec.InsertBulk(ctx, pool, q, users)
+//	// result.Skipped: indexes of users whose email already existed
+func InsertBulk(ctx context.Context, db Querier, pg pgstring.PgString, rows any) (BulkResult, error) {
+	val := reflect.ValueOf(rows)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Slice {
+		return BulkResult{}, fmt.Errorf("pgexec: InsertBulk needs a slice of structs, not %T", rows)
+	}
+
+	result, err := Query(ctx, db, pg)
+	if err != nil {
+		return BulkResult{}, err
+	}
+	defer result.Close()
+
+	matcher, err := newRowMatcher(val, result.FieldDescriptions())
+	if err != nil {
+		return BulkResult{}, err
+	}
+	for result.Next() {
+		returned, err := matcher.scan(result)
+		if err != nil {
+			return BulkResult{}, err
+		}
+		i, ok := matcher.match(returned)
+		if !ok {
+			return BulkResult{}, errors.New("pgexec: a returned row matches no element; RETURNING must include an identifying column")
+		}
+		elem := reflect.Indirect(val.Index(i))
+		for _, field := range matcher.fields {
+			if value := elem.Field(field.Index); value.IsZero() {
+				value.Set(returned.Field(field.Index))
+			}
+		}
+	}
+	if err := result.Err(); err != nil {
+		return BulkResult{}, err
+	}
+
+	var report BulkResult
+	for i, matched := range matcher.matched {
+		if matched {
+			report.Inserted = append(report.Inserted, i)
+		} else {
+			report.Skipped = append(report.Skipped, i)
+		}
+	}
+	return report, nil
+}
+
+// rowMatcher pairs the rows a bulk insert returns with the elements they were inserted
+// from, by the values of the returned columns every element has a value for
+type rowMatcher struct {
+	val      reflect.Value
+	elemType reflect.Type
+	// fields are the fields of the returned columns, keys the ones identifying the rows
+	fields []pgstring.StructField
+	keys   []pgstring.StructField
+	// byKey maps the key values of the elements to their indexes
+	byKey   map[string][]int
+	matched []bool
+}
+
+func newRowMatcher(val reflect.Value, descriptions []pgconn.FieldDescription) (*rowMatcher, error) {
+	elemType := val.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("pgexec: needs a slice of structs, not of %s", elemType)
+	}
+
+	m := &rowMatcher{
+		val:      val,
+		elemType: elemType,
+		fields:   returnedFields(elemType, descriptions),
+		byKey:    make(map[string][]int, val.Len()),
+		matched:  make([]bool, val.Len()),
+	}
+	elems := make([]reflect.Value, val.Len())
+	for i := range elems {
+		if elems[i] = reflect.Indirect(val.Index(i)); !elems[i].IsValid() {
+			return nil, fmt.Errorf("pgexec: nil element %d", i)
+		}
+	}
+	for _, field := range m.fields {
+		if !slices.ContainsFunc(elems, func(elem reflect.Value) bool { return elem.Field(field.Index).IsZero() }) {
+			m.keys = append(m.keys, field)
+		}
+	}
+	if len(m.keys) == 0 && len(elems) > 0 {
+		return nil, errors.New("pgexec: no returned column identifies the rows; RETURNING must include a column every element has a value for")
+	}
+	for i, elem := range elems {
+		key := m.key(elem)
+		m.byKey[key] = append(m.byKey[key], i)
+	}
+	return m, nil
+}
+
+// scan scans the current row into a new element
+func (m *rowMatcher) scan(rows pgx.Rows) (reflect.Value, error) {
+	returned := reflect.New(m.elemType).Elem()
+	dest, err := returningPointers(returned, rows.FieldDescriptions())
+	if err != nil {
+		return returned, err
+	}
+	return returned, rows.Scan(dest...)
+}
+
+// match returns the index of the first unmatched element returned was inserted from
+func (m *rowMatcher) match(returned reflect.Value) (int, bool) {
+	for _, i := range m.byKey[m.key(returned)] {
+		if !m.matched[i] {
+			m.matched[i] = true
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// key formats the key values of elem; times are compared as instants, since Postgres
+// returns them in another location and at microsecond precision
+func (m *rowMatcher) key(elem reflect.Value) string {
+	var b strings.Builder
+	for _, field := range m.keys {
+		value := reflect.Indirect(elem.Field(field.Index))
+		var v any
+		if value.IsValid() {
+			v = value.Interface()
+		}
+		if t, ok := v.(time.Time); ok {
+			v = t.Truncate(time.Microsecond).UTC()
+		}
+		fmt.Fprintf(&b, "%#v\x00", v)
+	}
+	return b.String()
+}
+
+// returnedFields returns the fields of typ for the returned columns
+func returnedFields(typ reflect.Type, descriptions []pgconn.FieldDescription) []pgstring.StructField {
+	var fields []pgstring.StructField
+	for _, field := range pgstring.StructFields(typ) {
+		for _, d := range descriptions {
+			if !field.Nested && strings.EqualFold(field.Column, d.Name) {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// ExecReturning runs pg, a write, and scans the written row into dest, a pointer to a
+// struct, so callers read their own write. Inserts, updates and deletes get a RETURNING
+// of dest's columns unless they have one; other statements, such as raw SQL, are followed
+// by a SELECT of dest by its primary key (see pgstring.SelectByPK) in the same transaction,
+// a savepoint when db already is one, so dest's primary key fields must be set beforehand:
+// a zero key fails before the statement runs. A write that affects no row returns
+// pgx.ErrNoRows.
+//
+//	var user User
+//	query := pgstring.Update("users").Set(changes).Where("id = @id", map[string]any{"id": id})
+//	err := pgexec.ExecReturning(ctx, pool, query, &user)
+func ExecReturning(ctx context.Context, db DB, pg pgstring.PgString, dest any) error {
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("pgexec: ExecReturning needs a pointer to a struct, not %T", dest)
+	}
+
+	switch pg.Kind() {
+	case pgstring.KindInsert, pgstring.KindUpdate, pgstring.KindDelete:
+		if !pg.HasReturning() {
+			pg = pg.Returning(dest)
+		}
+		return scanRow(ctx, db, pg, val)
+	}
+
+	if column, ok := zeroKey(val); ok {
+		return fmt.Errorf("pgexec: ExecReturning selects the written row by primary key, but %T has a zero %s", dest, column)
+	}
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		if _, err := Exec(ctx, tx, pg); err != nil {
+			return err
+		}
+		return scanRow(ctx, tx, pgstring.SelectByPK(dest), val)
+	})
+}
+
+// zeroKey returns the first primary key column whose field is zero in the struct dest
+// points to
+func zeroKey(dest reflect.Value) (string, bool) {
+	for _, field := range pgstring.StructFields(dest.Type()) {
+		if !field.Nested && field.HasOption("primarykey") && dest.Elem().Field(field.Index).IsZero() {
+			return field.Column, true
+		}
+	}
+	return "", false
+}
+
+// scanRow runs pg and scans its first row into the struct dest points to
+func scanRow(ctx context.Context, db Querier, pg pgstring.PgString, dest reflect.Value) error {
+	rows, err := Query(ctx, db, pg)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	pointers, err := returningPointers(dest, rows.FieldDescriptions())
+	if err != nil {
+		return err
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return err
+	}
+	rows.Close()
+	return rows.Err()
+}
@@ -0,0 +1,121 @@
+package pgexec_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oliverpaddock/pgstring"
+	"github.com/oliverpaddock/pgstring/pgexec"
+)
+
+type user struct {
+	ID    int    `db:"id,primarykey"`
+	Email string `db:"email"`
+	Name  string `db:"name"`
+}
+
+// fakeDB returns the same rows for every query
+type fakeDB struct {
+	columns []string
+	rows    [][]any
+}
+
+func (db fakeDB) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("INSERT 0 0"), nil
+}
+
+func (db fakeDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return &fakeRows{columns: db.columns, rows: db.rows, i: -1}, nil
+}
+
+func (db fakeDB) QueryRow(context.Context, string, ...any) pgx.Row {
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]any
+	i       int
+}
+
+func (r *fakeRows) Close()                        {}
+func (r *fakeRows) Err() error                    { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
+func (r *fakeRows) Next() bool                    { r.i++; return r.i < len(r.rows) }
+func (r *fakeRows) Values() ([]any, error)        { return r.rows[r.i], nil }
+func (r *fakeRows) RawValues() [][]byte           { return nil }
+func (r *fakeRows) Conn() *pgx.Conn               { return nil }
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	descriptions := make([]pgconn.FieldDescription, len(r.columns))
+	for i, column := range r.columns {
+		descriptions[i].Name = column
+	}
+	return descriptions
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	if len(dest) != len(r.rows[r.i]) {
+		return errors.New("wrong number of destinations")
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.rows[r.i][i]))
+	}
+	return nil
+}
+
+func TestScanReturningMatchesByKey(t *testing.T) {
+	users := []user{{Email: "a@example.com", Name: "A"}, {Email: "b@example.com", Name: "B"}}
+	// Rows come back in another order than they were inserted in
+	db := fakeDB{columns: []string{"id", "email"}, rows: [][]any{{20, "b@example.com"}, {10, "a@example.com"}}}
+	q := pgstring.InsertInto("users").Obj(user{}).ValuesBulk(users).Returning([]string{"id", "email"})
+
+	if err := pgexec.ScanReturning(context.Background(), db, q, users); err != nil {
+		t.Fatal(err)
+	}
+	want := []user{{ID: 10, Email: "a@example.com", Name: "A"}, {ID: 20, Email: "b@example.com", Name: "B"}}
+	if !reflect.DeepEqual(users, want) {
+		t.Errorf("got %+v, want %+v", users, want)
+	}
+}
+
+func TestScanReturningNeedsIdentifyingColumn(t *testing.T) {
+	users := []user{{Email: "a@example.com"}}
+	db := fakeDB{columns: []string{"id"}, rows: [][]any{{10}}}
+	q := pgstring.InsertInto("users").Obj(user{}).ValuesBulk(users).Returning("id")
+
+	if err := pgexec.ScanReturning(context.Background(), db, q, users); err == nil {
+		t.Error("expected an error without an identifying returned column")
+	}
+}
+
+func TestInsertBulkReportsSkipped(t *testing.T) {
+	users := []user{{Email: "a@example.com"}, {Email: "b@example.com"}, {Email: "c@example.com"}}
+	db := fakeDB{columns: []string{"id", "email"}, rows: [][]any{{30, "c@example.com"}, {10, "a@example.com"}}}
+	q := pgstring.InsertInto("users").Obj(user{}).ValuesBulk(users, pgstring.BulkSkipConflicts).
+		Returning([]string{"id", "email"})
+
+	result, err := pgexec.InsertBulk(context.Background(), db, q, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (pgexec.BulkResult{Inserted: []int{0, 2}, Skipped: []int{1}}); !reflect.DeepEqual(result, want) {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+	if users[0].ID != 10 || users[1].ID != 0 || users[2].ID != 30 {
+		t.Errorf("IDs not scanned into the inserted users: %+v", users)
+	}
+}
+
+func TestExecReturningNeedsKey(t *testing.T) {
+	raw := pgstring.RawSQL("UPDATE users SET name = upper(name) WHERE email = 'a@example.com'")
+	err := pgexec.ExecReturning(context.Background(), nil, raw, &user{})
+	if err == nil || !strings.Contains(err.Error(), "zero id") {
+		t.Errorf("got %v, want an error about the zero id", err)
+	}
+}
@@ -0,0 +1,82 @@
+package pgexec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oliverpaddock/pgstring"
+)
+
+// Router sends read-only statements to a replica and everything else to the primary
+type Router struct {
+	primary Querier
+	replica Querier
+	pools   map[string]Querier
+}
+
+// NewRouter creates a Router over a primary and a replica
+func NewRouter(primary, replica Querier) *Router {
+	return &Router{primary: primary, replica: replica}
+}
+
+// AddPool registers a named pool that statements select with their Pool option, e.g. a
+// reporting replica. Pools must be added before the Router is used.
+func (r *Router) AddPool(name string, db Querier) *Router {
+	if r.pools == nil {
+		r.pools = make(map[string]Querier)
+	}
+	r.pools[name] = db
+	return r
+}
+
+// For returns the Querier pg should run on: the pool named by its Pool option, else the
+// replica when pg.IsReadOnly() or it is marked ReadOnly, otherwise the primary. Reads that
+// must see the caller's own writes should use the primary directly. An unknown pool yields
+// a Querier that fails every statement.
+func (r *Router) For(pg pgstring.PgString) Querier {
+	opts := pg.ExecOptions()
+	if opts.Pool != "" {
+		if db, ok := r.pools[opts.Pool]; ok {
+			return db
+		}
+		return errQuerier{err: fmt.Errorf("unknown pool %q", opts.Pool)}
+	}
+	if opts.ReadOnly || pg.IsReadOnly() {
+		return r.replica
+	}
+	return r.primary
+}
+
+// Exec executes pg on the Querier chosen by For
+func (r *Router) Exec(ctx context.Context, pg pgstring.PgString) (pgconn.CommandTag, error) {
+	return Exec(ctx, r.For(pg), pg)
+}
+
+// Query runs pg on the Querier chosen by For
+func (r *Router) Query(ctx context.Context, pg pgstring.PgString) (pgx.Rows, error) {
+	return Query(ctx, r.For(pg), pg)
+}
+
+// QueryRow runs pg on the Querier chosen by For
+func (r *Router) QueryRow(ctx context.Context, pg pgstring.PgString) pgx.Row {
+	return QueryRow(ctx, r.For(pg), pg)
+}
+
+// errQuerier is a Querier that fails with err
+type errQuerier struct {
+	err error
+}
+
+func (q errQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, q.err
+}
+
+func (q errQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, q.err
+}
+
+func (q errQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return errRow{err: q.err}
+}
@@ -0,0 +1,87 @@
+package pgexec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oliverpaddock/pgstring"
+)
+
+// explainable are the statement kinds EXPLAIN accepts
+var explainable = map[string]bool{
+	"SELECT": true,
+	"INSERT": true,
+	"UPDATE": true,
+	"DELETE": true,
+	"WITH":   true,
+	"VALUES": true,
+}
+
+// isSlow reports whether an execution of pg that took elapsed exceeded its SlowThreshold
+func isSlow(pg pgstring.PgString, elapsed time.Duration) bool {
+	opts := pg.ExecOptions()
+	return opts.OnSlow != nil && elapsed > opts.SlowThreshold
+}
+
+// reportSlow calls pg's OnSlow when the execution exceeded its SlowThreshold. The plan is
+// fetched with a plain EXPLAIN, which doesn't run the statement again; it is skipped for
+// statements EXPLAIN doesn't accept and for failures that aborted the transaction.
+func reportSlow(ctx context.Context, db Querier, pg pgstring.PgString, sql string, namedArgs map[string]any, elapsed time.Duration, err error) {
+	if !isSlow(pg, elapsed) {
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+
+	slow := pgstring.SlowQuery{Duration: elapsed, Query: pg.DebugString()}
+	_, inTx := db.(pgx.Tx)
+	switch {
+	case !explainable[pg.Operation()]:
+		slow.PlanErr = errors.New("statement can't be explained")
+	case err != nil && inTx:
+		slow.PlanErr = errors.New("transaction aborted by the failed statement")
+	default:
+		slow.Plan, slow.PlanErr = explain(ctx, db, sql, namedArgs)
+	}
+	pg.ExecOptions().OnSlow(slow)
+}
+
+// explain returns the EXPLAIN output of sql, one plan line per output line
+func explain(ctx context.Context, db Querier, sql string, namedArgs map[string]any) (string, error) {
+	rows, err := db.Query(ctx, "EXPLAIN "+sql, args(namedArgs)...)
+	if err != nil {
+		return "", err
+	}
+	lines, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// slowRows reports its slow query once the rows are closed
+type slowRows struct {
+	pgx.Rows
+	report func()
+}
+
+func (r *slowRows) Close() {
+	r.Rows.Close()
+	if r.report != nil {
+		report := r.report
+		r.report = nil
+		report()
+	}
+}
+
+func (r *slowRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
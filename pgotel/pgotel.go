@@ -0,0 +1,152 @@
+// Package pgotel traces pgstring queries with OpenTelemetry, starting a span per
+// execution described by the builder's metadata.
+package pgotel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oliverpaddock/pgstring"
+	"github.com/oliverpaddock/pgstring/pgexec"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultMaxStatement is the length db.statement is truncated to unless set otherwise
+const DefaultMaxStatement = 2048
+
+// Tracer executes queries through pgexec inside spans
+type Tracer struct {
+	tracer trace.Tracer
+	// MaxStatement is the length db.statement is truncated to; 0 leaves it out
+	MaxStatement int
+}
+
+// NewTracer creates a Tracer from provider, or the global provider when it is nil
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{
+		tracer:       provider.Tracer("github.com/oliverpaddock/pgstring"),
+		MaxStatement: DefaultMaxStatement,
+	}
+}
+
+// Exec executes pg with pgexec.Exec in a span
+func (t *Tracer) Exec(ctx context.Context, db pgexec.Querier, pg pgstring.PgString) (pgconn.CommandTag, error) {
+	ctx, span := t.start(ctx, pg)
+	defer span.End()
+
+	tag, err := pgexec.Exec(ctx, db, pg)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", tag.RowsAffected()))
+	}
+	record(span, err)
+	return tag, err
+}
+
+// Query runs pg with pgexec.Query in a span that ends when the rows are closed
+func (t *Tracer) Query(ctx context.Context, db pgexec.Querier, pg pgstring.PgString) (pgx.Rows, error) {
+	ctx, span := t.start(ctx, pg)
+	rows, err := pgexec.Query(ctx, db, pg)
+	if err != nil {
+		record(span, err)
+		span.End()
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+// QueryRow runs pg with pgexec.QueryRow in a span that ends when the row is scanned
+func (t *Tracer) QueryRow(ctx context.Context, db pgexec.Querier, pg pgstring.PgString) pgx.Row {
+	ctx, span := t.start(ctx, pg)
+	return tracedRow{row: pgexec.QueryRow(ctx, db, pg), span: span}
+}
+
+// start starts a client span named after the operation and primary table of pg
+func (t *Tracer) start(ctx context.Context, pg pgstring.PgString) (context.Context, trace.Span) {
+	operation := pg.Operation()
+	read, written := pg.Tables()
+	tables := append(append([]string{}, written...), read...)
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", operation),
+	}
+	name := operation
+	if len(tables) > 0 {
+		name += " " + tables[0]
+		attrs = append(attrs,
+			attribute.String("db.sql.table", tables[0]),
+			attribute.StringSlice("db.sql.tables", tables),
+		)
+	}
+	if t.MaxStatement > 0 && pg.Err() == nil {
+		attrs = append(attrs, attribute.String("db.statement", truncate(pg.String(), t.MaxStatement)))
+	}
+	if name == "" {
+		name = "pgstring"
+	}
+	return t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
+
+// record marks span as failed with err, if any
+func record(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// tracedRows ends its span when the rows are closed
+type tracedRows struct {
+	pgx.Rows
+	span  trace.Span
+	ended bool
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	if !r.ended {
+		r.ended = true
+		record(r.span, r.Rows.Err())
+		r.span.End()
+	}
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+// tracedRow ends its span when the row is scanned
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r tracedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	record(r.span, err)
+	r.span.End()
+	return err
+}
@@ -0,0 +1,77 @@
+// Package pgprom exports the pgexec.Metrics of pgstring queries to Prometheus.
+package pgprom
+
+import (
+	"time"
+
+	"github.com/oliverpaddock/pgstring/pgexec"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a pgexec.Metrics recording Prometheus histograms and counters labeled by
+// statement kind and table
+type Metrics struct {
+	builds      *prometheus.HistogramVec
+	execs       *prometheus.HistogramVec
+	errors      *prometheus.CounterVec
+	buildErrors *prometheus.CounterVec
+}
+
+var _ pgexec.Metrics = (*Metrics)(nil)
+
+// New creates Metrics and registers its collectors with reg, or the default registerer
+// when reg is nil. Metric names are prefixed with namespace when it isn't empty.
+func New(reg prometheus.Registerer, namespace string) (*Metrics, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	labels := []string{"kind", "table"}
+	m := &Metrics{
+		builds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "pgstring_build_duration_seconds",
+			Help:      "Time spent rendering queries.",
+			Buckets:   []float64{.00001, .00005, .0001, .0005, .001, .005, .01},
+		}, labels),
+		execs: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "pgstring_exec_duration_seconds",
+			Help:      "Time spent executing queries.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pgstring_exec_errors_total",
+			Help:      "Queries that failed to execute.",
+		}, labels),
+		buildErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pgstring_build_errors_total",
+			Help:      "Queries that failed to build.",
+		}, labels),
+	}
+
+	for _, c := range []prometheus.Collector{m.builds, m.execs, m.errors, m.buildErrors} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ObserveBuild records the build duration, and counts build errors
+func (m *Metrics) ObserveBuild(duration time.Duration, statementKind, table string, err error) {
+	m.builds.WithLabelValues(statementKind, table).Observe(duration.Seconds())
+	if err != nil {
+		m.buildErrors.WithLabelValues(statementKind, table).Inc()
+	}
+}
+
+// ObserveExec records the execution duration, and counts execution errors
+func (m *Metrics) ObserveExec(duration time.Duration, statementKind, table string, err error) {
+	m.execs.WithLabelValues(statementKind, table).Observe(duration.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(statementKind, table).Inc()
+	}
+}
@@ -0,0 +1,55 @@
+package pgscan
+
+import (
+	"context"
+	"iter"
+	"reflect"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oliverpaddock/pgstring"
+	"github.com/oliverpaddock/pgstring/pgexec"
+)
+
+// Iterate runs pg and yields its rows one at a time, so large result sets can be processed
+// without loading them into a slice. Struct types are scanned with RowToStruct, other
+// types from a single column. Iteration stops after the first error; breaking out of the
+// loop closes the rows.
+//
+//	for order, err := range pgscan.Iterate[Order](ctx, pool, query) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Iterate[T any](ctx context.Context, db pgexec.Querier, pg pgstring.PgString) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		rows, err := pgexec.Query(ctx, db, pg)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		scan := rowFunc[T]()
+		for rows.Next() {
+			value, err := scan(rows)
+			if !yield(value, err) || err != nil {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, pgexec.Translate(err))
+		}
+	}
+}
+
+// rowFunc returns the scanning function for T
+func rowFunc[T any]() pgx.RowToFunc[T] {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Struct && t != reflect.TypeFor[time.Time]() && !reflect.PointerTo(t).Implements(scannerType) {
+		return RowToStruct[T]
+	}
+	return pgx.RowTo[T]
+}
@@ -0,0 +1,19 @@
+// Package pgscan scans pgx query results into Go values.
+package pgscan
+
+import (
+	"github.com/jackc/pgx/v5"
+)
+
+// ScanMaps reads every row into a map keyed by column name, for dynamic or reporting
+// queries whose shape isn't known at compile time, such as Crosstab pivots. rows is closed.
+func ScanMaps(rows pgx.Rows) ([]map[string]any, error) {
+	result, err := pgx.CollectRows(rows, pgx.RowToMap)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = []map[string]any{}
+	}
+	return result, nil
+}
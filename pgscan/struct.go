@@ -0,0 +1,256 @@
+package pgscan
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/oliverpaddock/pgstring"
+)
+
+// RowToStruct is a pgx.RowToFunc scanning a row into the struct T by column name, using
+// the same field naming as the builders. Fields tagged db:"name,nested" are scanned from
+// the columns prefixed with name_, as selected by pgstring.SelectPrefixed; T's own columns
+// may be prefixed too:
+//
+//	type Order struct {
+//		ID   int  `db:"id"`
+//		User User `db:"users,nested"`
+//	}
+//
+//	q := pgstring.SelectPrefixed("orders", &Order{}).SelectPrefixed("users", &User{}).
+//		From("orders").Join("INNER", "users", "users.id = orders.user_id")
+//	orders, err := pgx.CollectRows(rows, pgscan.RowToStruct[Order])
+//
+// A NULL column scanned into a non-pointer field is an error; see RowToStructWith.
+func RowToStruct[T any](row pgx.CollectableRow) (T, error) {
+	return scanStruct[T](row, Options{})
+}
+
+// Nulls controls how NULL columns are scanned into non-pointer fields
+type Nulls int
+
+const (
+	// NullStrict fails the scan, as pgx does
+	NullStrict Nulls = iota
+	// NullAsDefault sets the field to its db:",default=value" tag option, or its zero value
+	NullAsDefault
+)
+
+// Options configures struct scanning
+type Options struct {
+	Nulls Nulls
+}
+
+// RowToStructWith returns a RowToStruct scanning with opts:
+//
+//	type Stats struct {
+//		Views int    `db:"views"`                // 0 when NULL
+//		Label string `db:"label,default=none"`   // "none" when NULL
+//	}
+//
+//	stats, err := pgx.CollectRows(rows, pgscan.RowToStructWith[Stats](pgscan.Options{Nulls: pgscan.NullAsDefault}))
+func RowToStructWith[T any](opts Options) pgx.RowToFunc[T] {
+	return func(row pgx.CollectableRow) (T, error) {
+		return scanStruct[T](row, opts)
+	}
+}
+
+// scanStruct scans row into a new T
+func scanStruct[T any](row pgx.CollectableRow, opts Options) (T, error) {
+	var dest T
+	val := reflect.ValueOf(&dest).Elem()
+	if val.Kind() != reflect.Struct {
+		return dest, fmt.Errorf("pgscan: %s is not a struct", val.Type())
+	}
+
+	plan, err := planOf(val.Type(), row.FieldDescriptions())
+	if err != nil {
+		return dest, err
+	}
+
+	pointers := make([]any, len(plan))
+	var nullable []int
+	for i, target := range plan {
+		field := val.FieldByIndex(target.path)
+		if opts.Nulls == NullAsDefault && target.nullable {
+			if target.defaultErr != nil {
+				return dest, target.defaultErr
+			}
+			// Scan into a pointer, which pgx sets to nil for NULL
+			pointers[i] = reflect.New(reflect.PointerTo(target.field.Type)).Interface()
+			nullable = append(nullable, i)
+			continue
+		}
+		pointers[i] = target.field.Pointer(field)
+	}
+	if err := row.Scan(pointers...); err != nil {
+		return dest, err
+	}
+
+	for _, i := range nullable {
+		target := plan[i]
+		field := val.FieldByIndex(target.path).Field(target.field.Index)
+		if scanned := reflect.ValueOf(pointers[i]).Elem(); !scanned.IsNil() {
+			field.Set(scanned.Elem())
+		} else if target.defaultValue.IsValid() {
+			field.Set(target.defaultValue)
+		}
+	}
+	return dest, nil
+}
+
+// target is the destination of a column: field of the struct reached through path
+type target struct {
+	path  []int
+	field pgstring.StructField
+	// nullable is set for fields that can't hold NULL themselves
+	nullable     bool
+	defaultValue reflect.Value
+	defaultErr   error
+}
+
+// planKey identifies a scan plan: a struct type and the columns of a result set
+type planKey struct {
+	typ     reflect.Type
+	columns string
+}
+
+// plans caches scan plans, which are computed once per query shape
+var plans sync.Map
+
+// planOf maps every column of a result set to a field of typ
+func planOf(typ reflect.Type, descriptions []pgconn.FieldDescription) ([]target, error) {
+	names := make([]string, len(descriptions))
+	for i, d := range descriptions {
+		names[i] = strings.ToLower(d.Name)
+	}
+
+	key := planKey{typ: typ, columns: strings.Join(names, ",")}
+	if plan, ok := plans.Load(key); ok {
+		return plan.([]target), nil
+	}
+
+	root := make(map[string]target)
+	all := make(map[string]target)
+	collect(typ, nil, "", root, all)
+
+	plan := make([]target, len(names))
+	for i, name := range names {
+		t, ok := all[name]
+		if !ok {
+			// Columns of the root struct may carry its table prefix
+			for j := strings.IndexByte(name, '_'); j >= 0 && !ok; j = nextUnderscore(name, j) {
+				t, ok = root[name[j+1:]]
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("pgscan: column %s has no field in %s", descriptions[i].Name, typ)
+		}
+		plan[i] = t
+	}
+
+	plans.Store(key, plan)
+	return plan, nil
+}
+
+// collect adds the columns of typ, prefixed with prefix, to all; columns of the root
+// struct are also added to root
+func collect(typ reflect.Type, path []int, prefix string, root, all map[string]target) {
+	for _, field := range pgstring.StructFields(typ) {
+		fieldPath := append(path[:len(path):len(path)], field.Index)
+		name := prefix + strings.ToLower(field.Column)
+		if field.Nested {
+			collect(field.Type, fieldPath, name+"_", nil, all)
+			continue
+		}
+
+		// The field's own index is applied by Field.Pointer
+		t := target{path: path, field: field, nullable: !canHoldNull(field)}
+		if value, ok := field.OptionValue("default"); ok && t.nullable {
+			t.defaultValue, t.defaultErr = parseDefault(value, field.Type)
+			if t.defaultErr != nil {
+				t.defaultErr = fmt.Errorf("pgscan: default of %s: %w", field.Column, t.defaultErr)
+			}
+		}
+		all[name] = t
+		if root != nil {
+			root[name] = t
+		}
+	}
+}
+
+// nextUnderscore returns the index of the next underscore in name after i, or -1
+func nextUnderscore(name string, i int) int {
+	next := strings.IndexByte(name[i+1:], '_')
+	if next < 0 {
+		return -1
+	}
+	return i + 1 + next
+}
+
+// scannerType is the database/sql scanning interface
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+// canHoldNull reports whether a field scans NULL by itself: pointers, maps, slices,
+// interfaces, sql.Scanner implementations, and jsonb and transformed fields
+func canHoldNull(field pgstring.StructField) bool {
+	switch field.Type.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return true
+	}
+	if _, transformed := field.OptionValue("transform"); transformed || field.HasOption("encrypt") {
+		return true
+	}
+	return field.HasOption("jsonb") || reflect.PointerTo(field.Type).Implements(scannerType)
+}
+
+// parseDefault converts a default tag value to typ
+func parseDefault(value string, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.SetFloat(f)
+	default:
+		if typ == reflect.TypeFor[time.Time]() {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Set(reflect.ValueOf(t))
+			break
+		}
+		return reflect.Value{}, fmt.Errorf("defaults aren't supported for %s", typ)
+	}
+	return v, nil
+}
@@ -0,0 +1,100 @@
+// Package pgstat provides monitoring queries over the pg_stat views. Each query selects
+// columns in the field order of its result struct, so rows can be scanned with
+// pgstring.GenerateFieldPointers.
+package pgstat
+
+import (
+	"time"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+// Activity is a row returned by LongRunningQueries
+type Activity struct {
+	PID           int32   `db:"pid"`
+	User          *string `db:"usename"`
+	Database      *string `db:"datname"`
+	State         *string `db:"state"`
+	WaitEventType *string `db:"wait_event_type"`
+	WaitEvent     *string `db:"wait_event"`
+	Seconds       float64 `db:"duration_seconds"`
+	Query         string  `db:"query"`
+}
+
+// TableStats is a row returned by TableBloat
+type TableStats struct {
+	Schema          string     `db:"schemaname"`
+	Table           string     `db:"relname"`
+	LiveTuples      int64      `db:"n_live_tup"`
+	DeadTuples      int64      `db:"n_dead_tup"`
+	DeadRatio       float64    `db:"dead_ratio"`
+	TotalBytes      int64      `db:"total_bytes"`
+	LastVacuum      *time.Time `db:"last_vacuum"`
+	LastAutovacuum  *time.Time `db:"last_autovacuum"`
+	LastAutoanalyze *time.Time `db:"last_autoanalyze"`
+}
+
+// LockWait is a row returned by LockWaits
+type LockWait struct {
+	PID           int32   `db:"blocked_pid"`
+	User          *string `db:"blocked_user"`
+	Query         string  `db:"blocked_query"`
+	Seconds       float64 `db:"blocked_seconds"`
+	BlockingPID   int32   `db:"blocking_pid"`
+	BlockingUser  *string `db:"blocking_user"`
+	BlockingQuery string  `db:"blocking_query"`
+}
+
+// LongRunningQueries lists non-idle backends whose current query has run longer than min,
+// longest first
+func LongRunningQueries(min time.Duration) pgstring.PgString {
+	return pgstring.Select([]string{
+		"pid",
+		"usename::text",
+		"datname::text",
+		"state",
+		"wait_event_type",
+		"wait_event",
+		"EXTRACT(EPOCH FROM now() - query_start)::float8 AS duration_seconds",
+		"query",
+	}).
+		From("pg_stat_activity").
+		Where("state <> 'idle'").
+		AndWhere("pid <> pg_backend_pid()").
+		AndWhere("now() - query_start > make_interval(secs => @min_seconds)", map[string]any{"min_seconds": min.Seconds()}).
+		OrderBy("query_start")
+}
+
+// TableBloat estimates bloat of user tables from their dead tuple counts, most dead tuples first
+func TableBloat() pgstring.PgString {
+	return pgstring.Select([]string{
+		"schemaname::text",
+		"relname::text",
+		"n_live_tup",
+		"n_dead_tup",
+		"COALESCE(n_dead_tup::float8 / NULLIF(n_live_tup + n_dead_tup, 0), 0) AS dead_ratio",
+		"pg_total_relation_size(relid) AS total_bytes",
+		"last_vacuum",
+		"last_autovacuum",
+		"last_autoanalyze",
+	}).
+		From("pg_stat_user_tables").
+		OrderBy("n_dead_tup DESC")
+}
+
+// LockWaits lists backends waiting on a lock together with the backends blocking them,
+// longest wait first
+func LockWaits() pgstring.PgString {
+	return pgstring.Select([]string{
+		"blocked.pid AS blocked_pid",
+		"blocked.usename::text AS blocked_user",
+		"blocked.query AS blocked_query",
+		"EXTRACT(EPOCH FROM now() - blocked.query_start)::float8 AS blocked_seconds",
+		"blocking.pid AS blocking_pid",
+		"blocking.usename::text AS blocking_user",
+		"blocking.query AS blocking_query",
+	}).
+		From("pg_stat_activity blocked").
+		Join("INNER", "pg_stat_activity blocking", "blocking.pid = ANY(pg_blocking_pids(blocked.pid))").
+		OrderBy("blocked.query_start")
+}
@@ -1,9 +1,12 @@
 package pgstring
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -13,13 +16,42 @@ const (
 	TableOptionDropCascade = "DROP_CASCADE"
 )
 
+// MaxParams is the maximum number of bind parameters PostgreSQL accepts in a single statement
+const MaxParams = 65535
+
+// errNotStruct is recorded when a method that expects a struct receives something else
+var errNotStruct = errors.New("only struct types are supported")
+
+// PgString is an immutable query builder. Each method records a clause and returns a new
+// PgString; the SQL text is only rendered by String, Result, Build or WriteTo.
 type PgString struct {
-	str       string
-	fields    []string
-	namedArgs map[string]any
+	clauses    []clause
+	fields     []string
+	args       []namedArg
+	nullPolicy NullPolicy
+	sliceMode  SliceMode
+	// typeCheck is the model Build checks arg types against (see CheckTypes)
+	typeCheck reflect.Type
+	// inline makes Build inline args as literals (see InlineArgs)
+	inline bool
+	noTx   bool // must run outside a transaction block
+	exec   ExecOptions
+	// callerComment prefixes the built query with its Go call site
+	callerComment bool
+	// unchanged is set by SetDiff when no column changed
+	unchanged bool
+	// unmatched records what ValuesFrom left out of the column list
+	unmatched ColumnMismatch
+	// ordered places added clauses in SELECT clause order instead of last (see ParseSelect)
+	ordered bool
+	// sensitive lists the args Sensitive marked for redaction
+	sensitive []string
+	err       error
 }
 
-// GenerateFieldPointers creates a slice of pointers to struct fields based on db or json tags
+// GenerateFieldPointers creates a slice of pointers to struct fields based on db or json tags.
+// Fields tagged jsonb are wrapped in a sql.Scanner that unmarshals the column's JSON, and
+// transformed fields in one that applies their Transformer's Scan.
 func GenerateFieldPointers(obj any) []any {
 	// Ensure we have a pointer to a struct
 	v := reflect.ValueOf(obj)
@@ -37,246 +69,331 @@ func GenerateFieldPointers(obj any) []any {
 		return nil
 	}
 
-	typ := val.Type()
-	var pointers []any
-
-	for i := 0; i < val.NumField(); i++ {
-		field := typ.Field(i)
-
-		// Skip unexported fields
-		if field.PkgPath != "" {
-			continue
-		}
-
-		// Check for db tag, otherwise use JSON tag
-		dbTag := field.Tag.Get("db")
-		if dbTag == "-" {
-			continue
-		}
-
-		if dbTag == "" {
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == "-" {
-				continue
-			}
-
-			if jsonTag != "" {
-				parts := strings.Split(jsonTag, ",")
-				if parts[0] == "-" {
-					continue
-				}
-			}
-		}
-
+	info := structInfoOf(val.Type())
+	pointers := make([]any, len(info.fields))
+	for i, field := range info.fields {
 		// Get pointer to the field
-		fieldPtr := val.Field(i).Addr().Interface()
-		pointers = append(pointers, fieldPtr)
+		pointers[i] = val.Field(field.index).Addr().Interface()
+		if field.jsonb {
+			pointers[i] = jsonScanner{dest: pointers[i]}
+		} else if field.transform != "" {
+			pointers[i] = transformScanner{dest: pointers[i], name: field.transform}
+		}
 	}
 
 	return pointers
 }
 
 func (pg PgString) String() string {
-	return pg.str
+	return pg.render()
 }
 
 func (pg PgString) NamedArgs() map[string]any {
-	return pg.namedArgs
+	return pg.namedArgs()
 }
 
 func (pg PgString) Result() (string, map[string]any) {
-	return pg.str, pg.namedArgs
+	return pg.render(), pg.namedArgs()
 }
 
-// extractFields extracts field names from a struct and returns them as a slice
-func extractFields(obj any) []string {
-	val := reflect.ValueOf(obj)
-
-	// If pointer, get the underlying value
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
-	}
+// Build renders the query text and named args, returning any error recorded while building
+func (pg PgString) Build() (string, map[string]any, error) {
+	return pg.build(pg.inline || inlineArgsAll.Load())
+}
 
-	// Only struct types are supported
-	if val.Kind() != reflect.Struct {
-		return nil
+// build is Build, inlining the args when inline is set
+func (pg PgString) build(inline bool) (string, map[string]any, error) {
+	if pg.err != nil {
+		return "", nil, pg.err
 	}
-
-	typ := val.Type()
-	var fields []string
-
-	// Extract field names
-	for i := 0; i < val.NumField(); i++ {
-		field := typ.Field(i)
-
-		// Skip unexported fields
-		if field.PkgPath != "" {
-			continue
+	args := pg.namedArgs()
+	if pg.typeCheck != nil {
+		if err := pg.checkArgTypes(args); err != nil {
+			return "", nil, err
 		}
-
-		// Check for db tag, otherwise use field name
-		dbTag := field.Tag.Get("db")
-		fieldName := field.Name
-
-		if dbTag != "" && dbTag != "-" {
-			// Split on comma in case there are options like omitempty
-			parts := strings.Split(dbTag, ",")
-			fieldName = parts[0]
-		} else if dbTag == "-" {
-			continue
-		} else {
-			// Check for JSON tag if no db tag
-			jsonTag := field.Tag.Get("json")
-			if jsonTag != "" && jsonTag != "-" {
-				parts := strings.Split(jsonTag, ",")
-				if parts[0] != "" {
-					fieldName = parts[0]
-				}
-			}
+	}
+	sql := pg.render()
+	if inline {
+		var err error
+		if sql, err = inlineSQL(sql, args); err != nil {
+			return "", nil, err
 		}
-
-		fields = append(fields, fieldName)
+		args = nil
+	}
+	if pg.callerComment || callerComments.Load() {
+		return callerComment() + sql, args, nil
 	}
+	return sql, args, nil
+}
 
-	return fields
+// Err returns the first error recorded while building the query
+func (pg PgString) Err() error {
+	return pg.err
 }
 
-func extractNamedArgs(obj any) map[string]any {
-	result := map[string]any{}
+// fail records err on the query; the query renders as the error message from then on
+func (pg PgString) fail(err error) PgString {
+	if pg.err == nil {
+		pg.err = err
+	}
+	return pg
+}
 
-	v := reflect.ValueOf(obj)
+// extractFields extracts field names from a struct and returns them as a slice
+func extractFields(obj any) []string {
+	val, ok := structValue(obj)
 
-	// If pointer, get the underlying element
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
+	// Only struct types are supported
+	if !ok {
+		return nil
 	}
 
-	// Only process if it's a struct
-	if v.Kind() != reflect.Struct {
-		return result
-	}
+	return structInfoOf(val.Type()).names
+}
 
-	t := v.Type()
+// extractNamedArgs extracts field values from a struct as named args, in field order.
+// Zero values are bound according to policy and the fields' null/notnullzero tag options.
+func extractNamedArgs(obj any, policy NullPolicy) ([]namedArg, error) {
+	v, ok := structValue(obj)
 
-	// Iterate through each field in the struct
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+	// Only process if it's a struct
+	if !ok {
+		return nil, nil
+	}
 
-		// Skip unexported fields
-		if field.PkgPath != "" {
-			continue
-		}
-
-		// Get the db tag, if no tag, use field name
-		dbTag := field.Tag.Get("db")
-		if dbTag == "" {
-			// Skip if tag is explicitly set to "-"
-			if field.Tag.Get("json") == "-" {
-				continue
-			}
-
-			// Use JSON tag if available
-			jsonTag := field.Tag.Get("json")
-			if jsonTag != "" {
-				// Handle cases where json tag has options like "name,omitempty"
-				parts := strings.Split(jsonTag, ",")
-				if parts[0] != "" {
-					dbTag = parts[0]
-				} else {
-					dbTag = field.Name
-				}
-			} else {
-				// Fallback to field name
-				dbTag = field.Name
-			}
-		} else if dbTag == "-" {
-			// Skip if db tag is explicitly set to "-"
-			continue
-		} else {
-			// Handle db tag with options like "name,omitempty"
-			parts := strings.Split(dbTag, ",")
-			dbTag = parts[0]
+	info := structInfoOf(v.Type())
+	result := make([]namedArg, len(info.fields))
+	for i, field := range info.fields {
+		value, err := field.bindValue(v, policy)
+		if err != nil {
+			return nil, err
 		}
+		result[i] = namedArg{name: field.name, value: value, sensitive: field.sensitive}
+	}
 
-		// Get field value
-		fieldValue := v.Field(i)
+	return result, nil
+}
 
-		// Add to named args
-		result[dbTag] = fieldValue.Interface()
+// withStructArgs adds the fields of a struct as named args
+func (pg PgString) withStructArgs(obj any) PgString {
+	namedArgs, err := extractNamedArgs(obj, pg.nullPolicy)
+	if err != nil {
+		return pg.fail(err)
 	}
+	pg.args = append(slices.Clip(pg.args), namedArgs...)
+	return pg
+}
 
-	return result
+// NullPolicy sets how zero values of struct fields passed to later Values, ValuesBulk, Set
+// and condition methods are bound. The default, ZeroAsValue, binds them as they are.
+func (pg PgString) NullPolicy(policy NullPolicy) PgString {
+	pg.nullPolicy = policy
+	return pg
 }
 
 // InsertInto creates a new PgString for an INSERT query
 func InsertInto(table string) PgString {
-	return PgString{
-		str:       fmt.Sprintf("INSERT INTO %s", table),
-		namedArgs: map[string]any{},
-	}
+	auditIdent("InsertInto", table)
+	return PgString{}.with(clause{kind: clauseInsert, sql: table, table: tableName(table), writes: true})
 }
 
-// Obj extracts field names from the provided object and adds them to the query
+// Obj extracts field names from the provided object and adds them to the query.
+// Generated columns are left out since they can't be inserted.
 func (pg PgString) Obj(obj any) PgString {
-	fields := extractFields(obj)
+	val, ok := structValue(obj)
 
-	if fields == nil {
-		return PgString{str: "Error: only struct types are supported", namedArgs: pg.namedArgs}
+	if !ok {
+		return pg.fail(errNotStruct)
 	}
 
+	fields := structInfoOf(val.Type()).writable
+
 	pg.fields = fields
-	pg.str = fmt.Sprintf("%s (%s)", pg.str, strings.Join(fields, ", "))
-	return pg
+	return pg.with(clause{kind: clauseColumns, fields: fields})
 }
 
-// Values extracts values from the provided object and adds placeholders to the query
+// Values extracts values from the provided object and adds placeholders to the query.
+// obj must have the fields of the column list Obj added, so passing a different struct
+// type to Obj and Values is an error rather than a misaligned INSERT.
 func (pg PgString) Values(obj any) PgString {
-	val := reflect.ValueOf(obj)
+	// Only struct types are supported
+	val, ok := structValue(obj)
+	if !ok {
+		return pg.fail(errNotStruct)
+	}
+	obj, err := beforeInsert(obj)
+	if err != nil {
+		return pg.fail(err)
+	}
+	if err := checkColumns(pg.fields, structInfoOf(val.Type()).writable); err != nil {
+		return pg.fail(fmt.Errorf("Values(%s): %w", val.Type(), err))
+	}
+
+	// Collect named arguments
+	pg = pg.withStructArgs(obj)
+
+	// Generate placeholders for the values
+	return pg.with(clause{kind: clauseValues, fields: pg.fields})
+}
+
+// Map adds the column list and VALUES clause of an INSERT from a map of column names to values.
+// Columns are sorted so the generated SQL is stable.
+func (pg PgString) Map(row map[string]any) PgString {
+	columns := slices.Sorted(maps.Keys(row))
+
+	pg.fields = columns
+	pg.args = slices.Grow(slices.Clip(pg.args), len(columns))
+	for _, column := range columns {
+		pg.args = append(pg.args, namedArg{name: column, value: row[column]})
+	}
+
+	return pg.with(clause{kind: clauseColumns, fields: columns}).
+		with(clause{kind: clauseValues, fields: columns})
+}
+
+// ValuesBulk adds a multi-row VALUES clause with one set of placeholders per element of objs.
+// Placeholders are suffixed with the row index (@name_0, @name_1, ...). Use Chunks to split
+// the statement when the rows need more than MaxParams parameters. options are BulkOption
+// values, e.g. BulkSkipConflicts.
+func (pg PgString) ValuesBulk(objs any, options ...BulkOption) PgString {
+	val := reflect.ValueOf(objs)
 
 	// If pointer, get the underlying value
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
 
-	// Only struct types are supported
-	if val.Kind() != reflect.Struct {
-		return PgString{str: "Error: only struct types are supported", namedArgs: pg.namedArgs}
+	// Only slices of structs are supported
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return pg.fail(errors.New("only slices of structs are supported"))
+	}
+	if val.Len() == 0 {
+		return pg.fail(errors.New("ValuesBulk: no rows"))
 	}
 
-	// Collect named arguments
-	namedArgs := extractNamedArgs(obj)
-	pg.namedArgs = namedArgs
-
-	// Generate placeholders for the values
-	placeholders := make([]string, len(pg.fields))
-	for i, field := range pg.fields {
-		placeholders[i] = fmt.Sprintf("@%s", field)
+	rows := make([][]any, val.Len())
+	var sensitive []string
+	for i := range rows {
+		obj, err := beforeInsert(elemModel(val.Index(i)))
+		if err != nil {
+			return pg.fail(fmt.Errorf("row %d: %w", i, err))
+		}
+		elem, ok := structValue(obj)
+		if !ok {
+			return pg.fail(errors.New("only slices of structs are supported"))
+		}
+		if err := checkColumns(pg.fields, structInfoOf(elem.Type()).writable); err != nil {
+			return pg.fail(fmt.Errorf("row %d: %w", i, err))
+		}
+		row, err := rowValues(elem, pg.fields, pg.nullPolicy)
+		if err != nil {
+			return pg.fail(fmt.Errorf("row %d: %w", i, err))
+		}
+		rows[i] = row
+		if i == 0 {
+			sensitive = sensitiveColumns(elem.Type())
+		}
 	}
 
-	pg.str = fmt.Sprintf("%s VALUES (%s)", pg.str, strings.Join(placeholders, ", "))
-	return pg
+	return pg.with(clause{kind: clauseValues, fields: pg.fields, rows: rows, sensitive: sensitive}).withBulkOptions(options)
 }
 
-// Where adds a WHERE clause to the query
-func (pg PgString) Where(condition string, args ...any) PgString {
-	pg.str = fmt.Sprintf("%s WHERE %s", pg.str, condition)
+// checkColumns returns an error unless a struct's writable fields are exactly columns
+func checkColumns(columns, fields []string) error {
+	if len(columns) == 0 {
+		return errors.New("no column list, call Obj first")
+	}
+	for _, column := range columns {
+		if !slices.Contains(fields, column) {
+			return fmt.Errorf("column %s has no field", column)
+		}
+	}
+	for _, field := range fields {
+		if !slices.Contains(columns, field) {
+			return fmt.Errorf("field %s isn't in the column list", field)
+		}
+	}
+	return nil
+}
 
-	// If additional args are provided, add them to namedArgs
-	if len(args) == 1 {
-		if obj, ok := args[0].(map[string]any); ok {
-			for k, v := range obj {
-				pg.namedArgs[k] = v
+// rowValues returns the values of a struct's fields in the order of columns
+func rowValues(val reflect.Value, columns []string, policy NullPolicy) ([]any, error) {
+	info := structInfoOf(val.Type())
+	row := make([]any, len(columns))
+	for i, column := range columns {
+		for _, field := range info.fields {
+			if field.name == column {
+				value, err := field.bindValue(val, policy)
+				if err != nil {
+					return nil, err
+				}
+				row[i] = value
+				break
 			}
-		} else {
-			// Extract named args from struct
-			namedArgs := extractNamedArgs(args[0])
-			for k, v := range namedArgs {
-				pg.namedArgs[k] = v
+		}
+	}
+	return row, nil
+}
+
+// bulkArgName returns the named arg used for a column of a bulk VALUES row
+func bulkArgName(field string, row int) string {
+	return field + "_" + strconv.Itoa(row)
+}
+
+// writeBulkValues writes a VALUES clause for n rows of fields
+func writeBulkValues(w sqlWriter, fields []string, n int) {
+	w.WriteString("VALUES ")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		w.WriteByte('(')
+		for j, field := range fields {
+			if j > 0 {
+				w.WriteString(", ")
 			}
+			w.WriteByte('@')
+			w.WriteString(bulkArgName(field, i))
 		}
+		w.WriteByte(')')
+	}
+}
+
+// Chunks splits a ValuesBulk query into as many statements as needed to keep each one within
+// MaxParams parameters. Clauses added after ValuesBulk (ON CONFLICT, RETURNING, ...) are
+// repeated in every chunk. Queries that already fit are returned as a single element.
+func (pg PgString) Chunks() []PgString {
+	bulk := slices.IndexFunc(pg.clauses, func(c clause) bool { return c.rows != nil })
+	if bulk < 0 || len(pg.fields) == 0 || pg.paramCount() <= MaxParams {
+		return []PgString{pg}
 	}
 
-	return pg
+	// Args that don't belong to the VALUES rows are shared by every chunk
+	size := (MaxParams - len(pg.args)) / len(pg.clauses[bulk].fields)
+	if size < 1 {
+		size = 1
+	}
+
+	rows := pg.clauses[bulk].rows
+	var chunks []PgString
+	for start := 0; start < len(rows); start += size {
+		chunk := pg
+		chunk.clauses = slices.Clone(pg.clauses)
+		chunk.clauses[bulk].rows = rows[start:min(start+size, len(rows))]
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// Where adds a WHERE clause to the query. Further conditions are joined with AND, each
+// in parentheses, so Where("a OR b").Where("c") renders WHERE (a OR b) AND (c).
+// Slice values in an arg map used as IN (@name) are bound according to SliceMode.
+func (pg PgString) Where(condition string, args ...any) PgString {
+	auditFragment("Where", condition)
+	condition, args = pg.expandArgs(condition, args)
+	// If additional args are provided, add them to namedArgs
+	return pg.with(clause{kind: clauseWhere, sql: condition}).withArgs(args)
 }
 
 // Select creates a new PgString for a SELECT query with explicit fields from an object
@@ -286,147 +403,222 @@ func Select(obj any) PgString {
 	if fields == nil {
 		// If not an object, treat it as a list of field names
 		if strArgs, ok := obj.([]string); ok {
-			return PgString{
-				str:       fmt.Sprintf("SELECT %s", strings.Join(strArgs, ", ")),
-				namedArgs: map[string]any{},
-			}
+			return PgString{}.with(clause{kind: clauseSelect, fields: strArgs})
 		}
 
 		// If it's a string, just use that directly
 		if strArg, ok := obj.(string); ok {
-			return PgString{
-				str:       fmt.Sprintf("SELECT %s", strArg),
-				namedArgs: map[string]any{},
-			}
+			auditFragment("Select", strArg)
+			return PgString{}.with(clause{kind: clauseSelect, sql: strArg})
 		}
 
-		// Default to SELECT *
-		return PgString{
-			str:       "SELECT *",
-			namedArgs: map[string]any{},
+		// Expressions bind their args
+		if e, ok := obj.(Expr); ok {
+			return PgString{}.with(clause{kind: clauseSelect, sql: e.sql}).withExpr(e)
 		}
+
+		// Default to SELECT *
+		return PgString{}.with(clause{kind: clauseSelect, sql: "*"})
 	}
 
-	// Use extracted fields from object
-	return PgString{
-		str:       fmt.Sprintf("SELECT %s", strings.Join(fields, ", ")),
-		fields:    fields,
-		namedArgs: extractNamedArgs(obj),
+	// Use extracted fields from object; grouping fields select a GROUPING() expression
+	pg := PgString{fields: fields}.withStructArgs(obj)
+	val, _ := structValue(obj)
+	return pg.with(clause{kind: clauseSelect, fields: structInfoOf(val.Type()).selects})
+}
+
+// SelectPrefixed creates a SELECT query with the fields of obj qualified by table and
+// aliased with its prefix, users.id AS users_id, so joined tables with the same column
+// names can be selected together and scanned into nested structs with pgscan.RowToStruct
+func SelectPrefixed(table string, obj any) PgString {
+	return PgString{}.SelectPrefixed(table, obj)
+}
+
+// SelectPrefixed adds the prefixed fields of another table's struct to the SELECT list
+func (pg PgString) SelectPrefixed(table string, obj any) PgString {
+	fields := extractFields(obj)
+	if fields == nil {
+		return pg.fail(errNotStruct)
+	}
+
+	prefixed := make([]string, len(fields))
+	for i, field := range fields {
+		prefixed[i] = table + "." + field + " AS " + table + "_" + field
+	}
+
+	if n := len(pg.clauses); n > 0 && pg.clauses[n-1].kind == clauseSelect {
+		// Extend the SELECT list of the previous call
+		pg.clauses = slices.Clone(pg.clauses)
+		last := &pg.clauses[n-1]
+		if last.fields == nil && last.sql != "" {
+			last.fields = []string{last.sql}
+			last.sql = ""
+		}
+		last.fields = append(slices.Clip(last.fields), prefixed...)
+		return pg
 	}
+	return pg.with(clause{kind: clauseSelect, fields: prefixed})
 }
 
 // SelectStr creates a SELECT query with manually specified fields
 func SelectStr(fields ...string) PgString {
-	return PgString{
-		str:       fmt.Sprintf("SELECT %s", strings.Join(fields, ", ")),
-		fields:    fields,
-		namedArgs: map[string]any{},
-	}
+	pg := PgString{fields: fields}
+	return pg.with(clause{kind: clauseSelect, fields: fields})
 }
 
 // From adds a FROM clause to the query
 func (pg PgString) From(table string) PgString {
-	pg.str = fmt.Sprintf("%s FROM %s", pg.str, table)
-	return pg
+	auditIdent("From", table)
+	return pg.with(clause{kind: clauseFrom, sql: table, table: tableName(table)})
+}
+
+// TableSample adds a TABLESAMPLE clause to the preceding FROM table, reading roughly
+// percentage percent of it for approximate analytics. method is BERNOULLI (sampled rows) or
+// SYSTEM (sampled pages, faster); a seed makes the sample REPEATABLE.
+func (pg PgString) TableSample(method string, percentage float64, seed ...int64) PgString {
+	method = strings.ToUpper(method)
+	if method != "BERNOULLI" && method != "SYSTEM" {
+		return pg.fail(fmt.Errorf("unsupported sampling method %q", method))
+	}
+	if percentage < 0 || percentage > 100 {
+		return pg.fail(fmt.Errorf("sample percentage %v is outside 0-100", percentage))
+	}
+
+	sample := method + " (" + strconv.FormatFloat(percentage, 'g', -1, 64) + ")"
+	if len(seed) > 0 {
+		sample += " REPEATABLE (" + strconv.FormatInt(seed[0], 10) + ")"
+	}
+	return pg.with(clause{kind: clauseTableSample, sql: sample})
 }
 
 // Update creates a new PgString for an UPDATE query
 func Update(table string) PgString {
-	return PgString{
-		str:       fmt.Sprintf("UPDATE %s", table),
-		namedArgs: map[string]any{},
-	}
+	auditIdent("Update", table)
+	return PgString{}.with(clause{kind: clauseUpdate, sql: table, table: tableName(table), writes: true})
 }
 
-// Set adds a SET clause for an UPDATE query
+// Set adds a SET clause for an UPDATE query. obj is a struct, whose columns are assigned in
+// field order, or a map of columns to values, assigned sorted by column, where Expr values
+// are assigned as expressions, e.g. {"price": Col("price").Mul(Arg("factor", 1.1))}.
 func (pg PgString) Set(obj any) PgString {
-	val := reflect.ValueOf(obj)
-
-	// If pointer, get the underlying value
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
+	if values, ok := obj.(map[string]any); ok {
+		return pg.setMap(values)
 	}
 
 	// Only struct types are supported
-	if val.Kind() != reflect.Struct {
-		return PgString{str: "Error: only struct types are supported", namedArgs: pg.namedArgs}
+	if _, ok := structValue(obj); !ok {
+		return pg.fail(errNotStruct)
+	}
+	// An upsert's DO UPDATE SET binds the same args as its VALUES, so it runs the same hook
+	hook := beforeUpdate
+	if pg.Kind() == KindInsert {
+		hook = beforeInsert
+	}
+	obj, err := hook(obj)
+	if err != nil {
+		return pg.fail(err)
 	}
 
 	// Extract named args and fields
-	namedArgs := extractNamedArgs(obj)
-	pg.namedArgs = namedArgs
-
-	var setters []string
-
-	// Generate field=@field pairs using the actual field names from the object
-	for key := range namedArgs {
-		setters = append(setters, fmt.Sprintf("%s = @%s", key, key))
+	namedArgs, err := extractNamedArgs(obj, pg.nullPolicy)
+	if err != nil {
+		return pg.fail(err)
 	}
+	pg.args = append(slices.Clip(pg.args), namedArgs...)
+
+	// Generate field=@field pairs using the actual field names from the object,
+	// skipping generated columns
+	writable := structInfoOf(reflect.Indirect(reflect.ValueOf(obj)).Type()).writable
+	setters := make([]string, len(writable))
+	for i, name := range writable {
+		setters[i] = quoteColumn(name) + " = @" + name
+	}
+	return pg.with(clause{kind: clauseSet, fields: setters})
+}
 
-	// Sort for consistent output
-	sort.Strings(setters)
-
-	pg.str = fmt.Sprintf("%s SET %s", pg.str, strings.Join(setters, ", "))
-	return pg
+// setMap adds a SET clause assigning each column of values, sorted by column
+func (pg PgString) setMap(values map[string]any) PgString {
+	columns := slices.Sorted(maps.Keys(values))
+	setters := make([]string, len(columns))
+	for i, column := range columns {
+		if e, ok := values[column].(Expr); ok {
+			setters[i] = quoteColumn(column) + " = " + e.sql
+			pg = pg.withExpr(e)
+		} else {
+			setters[i] = quoteColumn(column) + " = @" + column
+			pg = pg.withArg(column, values[column])
+		}
+	}
+	return pg.with(clause{kind: clauseSet, fields: setters})
 }
 
 // Delete creates a new PgString for a DELETE query
 func Delete() PgString {
-	return PgString{
-		str:       "DELETE",
-		namedArgs: map[string]any{},
-	}
+	return PgString{}.with(clause{kind: clauseDelete})
 }
 
 // OrderBy adds an ORDER BY clause to the query
-func (pg PgString) OrderBy(clause string) PgString {
-	pg.str = fmt.Sprintf("%s ORDER BY %s", pg.str, clause)
-	return pg
+func (pg PgString) OrderBy(order string) PgString {
+	auditFragment("OrderBy", order)
+	return pg.with(clause{kind: clauseOrderBy, sql: order})
+}
+
+// OrderByCollate adds an ORDER BY item sorting column with collation, for locale-aware
+// ordering. dir is "ASC", "DESC" or empty for the default order.
+func (pg PgString) OrderByCollate(column, collation, dir string) PgString {
+	dir = strings.ToUpper(strings.TrimSpace(dir))
+	if dir != "" && dir != "ASC" && dir != "DESC" {
+		return pg.fail(fmt.Errorf("invalid sort direction %q", dir))
+	}
+
+	order := column + " COLLATE " + quoteCollation(collation)
+	if dir != "" {
+		order += " " + dir
+	}
+	return pg.with(clause{kind: clauseOrderBy, sql: order})
+}
+
+// quoteCollation double-quotes a collation name unless it is already quoted
+func quoteCollation(collation string) string {
+	if strings.HasPrefix(collation, `"`) {
+		return collation
+	}
+	return `"` + strings.ReplaceAll(collation, `"`, `""`) + `"`
+}
+
+// quoteIdent renders name as a double-quoted identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteLiteral renders text as a single-quoted string literal
+func quoteLiteral(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
 }
 
-// Limit adds a LIMIT clause to the query
+// Limit sets the LIMIT clause of the query, replacing an earlier one
 func (pg PgString) Limit(limit int) PgString {
-	pg.str = fmt.Sprintf("%s LIMIT %d", pg.str, limit)
-	return pg
+	return pg.with(clause{kind: clauseLimit, sql: strconv.Itoa(limit)})
 }
 
-// Offset adds an OFFSET clause to the query
+// Offset sets the OFFSET clause of the query, replacing an earlier one
 func (pg PgString) Offset(offset int) PgString {
-	pg.str = fmt.Sprintf("%s OFFSET %d", pg.str, offset)
-	return pg
+	return pg.with(clause{kind: clauseOffset, sql: strconv.Itoa(offset)})
 }
 
-// Join adds a JOIN clause to the query
-func (pg PgString) Join(joinType, table, condition string) PgString {
-	pg.str = fmt.Sprintf("%s %s JOIN %s ON %s", pg.str, joinType, table, condition)
-	return pg
+// Join adds a JOIN clause to the query. Args (a map or struct) bind the condition's
+// placeholders like Where's do, e.g. "o.user_id = u.id AND o.status = @status".
+func (pg PgString) Join(joinType, table, condition string, args ...any) PgString {
+	auditIdent("Join", table)
+	auditFragment("Join", condition)
+	condition, args = pg.expandArgs(condition, args)
+	return pg.with(clause{kind: clauseJoin, sql: joinType + " JOIN " + table + " ON " + condition, table: tableName(table)}).withArgs(args)
 }
 
 // AndWhere adds an AND condition to an existing WHERE clause
 func (pg PgString) AndWhere(condition string, args ...any) PgString {
-	// Check if WHERE clause already exists
-	if !strings.Contains(pg.str, " WHERE ") {
-		return pg.Where(condition, args...)
-	}
-
-	pg.str = fmt.Sprintf("%s AND %s", pg.str, condition)
-
-	// If additional args are provided, add them to namedArgs
-	if len(args) == 1 {
-		if obj, ok := args[0].(map[string]any); ok {
-			for k, v := range obj {
-				pg.namedArgs[k] = v
-			}
-		} else {
-			// Extract named args from struct
-			namedArgs := extractNamedArgs(args[0])
-			for k, v := range namedArgs {
-				pg.namedArgs[k] = v
-			}
-		}
-	}
-
-	return pg
+	// The first condition in a run of WHERE clauses renders as WHERE, the rest as AND
+	return pg.Where(condition, args...)
 }
 
 // Returning adds a RETURNING clause to the query
@@ -436,94 +628,196 @@ func (pg PgString) Returning(obj any) PgString {
 	if fields == nil {
 		// If it's a string, use it directly
 		if strArg, ok := obj.(string); ok {
-			pg.str = fmt.Sprintf("%s RETURNING %s", pg.str, strArg)
-			return pg
+			return pg.with(clause{kind: clauseReturning, sql: strArg})
 		}
 
 		// If it's a string slice, join them
 		if strArgs, ok := obj.([]string); ok {
-			pg.str = fmt.Sprintf("%s RETURNING %s", pg.str, strings.Join(strArgs, ", "))
-			return pg
+			return pg.with(clause{kind: clauseReturning, fields: strArgs})
 		}
 
 		// Default to RETURNING *
-		pg.str = fmt.Sprintf("%s RETURNING *", pg.str)
-		return pg
+		return pg.with(clause{kind: clauseReturning, sql: "*"})
 	}
 
 	// Use extracted fields
-	pg.str = fmt.Sprintf("%s RETURNING %s", pg.str, strings.Join(fields, ", "))
-	return pg
+	return pg.with(clause{kind: clauseReturning, fields: fields})
+}
+
+// HasReturning reports whether the statement has a RETURNING clause
+func (pg PgString) HasReturning() bool {
+	return slices.ContainsFunc(pg.clauses, func(c clause) bool { return c.kind == clauseReturning })
+}
+
+// ReturningQualified adds RETURNING fields qualified with table (or its alias), so an
+// UPDATE ... FROM can return columns of the target and joined tables unambiguously.
+// Consecutive Returning calls are merged into one list:
+//
+//	Update("orders o").Set(...).From("users u").Where("u.id = o.user_id").
+//		ReturningQualified("o", &Order{}).ReturningQualified("u", []string{"email"})
+func (pg PgString) ReturningQualified(table string, obj any) PgString {
+	fields := extractFields(obj)
+	if fields == nil {
+		switch v := obj.(type) {
+		case string:
+			fields = []string{v}
+		case []string:
+			fields = v
+		default:
+			fields = []string{"*"}
+		}
+	}
+
+	qualified := make([]string, len(fields))
+	for i, field := range fields {
+		qualified[i] = table + "." + field
+	}
+	return pg.with(clause{kind: clauseReturning, fields: qualified})
 }
 
 // GroupBy adds a GROUP BY clause to the query
-func (pg PgString) GroupBy(clause string) PgString {
-	pg.str = fmt.Sprintf("%s GROUP BY %s", pg.str, clause)
-	return pg
+func (pg PgString) GroupBy(columns string) PgString {
+	auditFragment("GroupBy", columns)
+	return pg.with(clause{kind: clauseGroupBy, sql: columns})
 }
 
 // Having adds a HAVING clause to the query
 func (pg PgString) Having(condition string, args ...any) PgString {
-	pg.str = fmt.Sprintf("%s HAVING %s", pg.str, condition)
-
+	auditFragment("Having", condition)
+	condition, args = pg.expandArgs(condition, args)
 	// If additional args are provided, add them to namedArgs
-	if len(args) == 1 {
-		if obj, ok := args[0].(map[string]any); ok {
-			for k, v := range obj {
-				pg.namedArgs[k] = v
-			}
-		} else {
-			// Extract named args from struct
-			namedArgs := extractNamedArgs(args[0])
-			for k, v := range namedArgs {
-				pg.namedArgs[k] = v
-			}
-		}
+	return pg.with(clause{kind: clauseHaving, sql: condition}).withArgs(args)
+}
+
+// comparisonOps are the operators the comparison helpers accept
+var comparisonOps = map[string]bool{"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// HavingCount adds a HAVING COUNT(*) op @having_count condition, e.g. HavingCount(">=", 5)
+func (pg PgString) HavingCount(op string, n int) PgString {
+	if !comparisonOps[op] {
+		return pg.fail(fmt.Errorf("HavingCount: unsupported operator %q", op))
 	}
+	return pg.with(clause{kind: clauseHaving, sql: "COUNT(*) " + op + " @having_count"}).withArg("having_count", n)
+}
 
-	return pg
+// HavingSum adds a HAVING SUM(column) op @column_sum condition, e.g. HavingSum("amount", ">", 1000)
+func (pg PgString) HavingSum(column, op string, value any) PgString {
+	auditIdent("HavingSum", column)
+	if !comparisonOps[op] {
+		return pg.fail(fmt.Errorf("HavingSum: unsupported operator %q", op))
+	}
+	name := argName(column) + "_sum"
+	return pg.with(clause{kind: clauseHaving, sql: "SUM(" + column + ") " + op + " @" + name}).withArg(name, value)
+}
+
+// OnConflict adds an ON CONFLICT clause for the conflict target, e.g. "(id)", or "" to
+// match any constraint
+func (pg PgString) OnConflict(target string) PgString {
+	return pg.with(clause{kind: clauseOnConflict, sql: target})
+}
+
+// OnConflictOnConstraint adds ON CONFLICT ON CONSTRAINT name, targeting a unique or
+// exclusion constraint by name
+func (pg PgString) OnConflictOnConstraint(name string) PgString {
+	return pg.with(clause{kind: clauseOnConflict, sql: "ON CONSTRAINT " + name})
 }
 
-func (pg PgString) OnConflict(clause string) PgString {
-	if clause == "" {
-		pg.str = fmt.Sprintf("%s ON CONFLICT", pg.str)
-	} else {
-		pg.str = fmt.Sprintf("%s ON CONFLICT %s", pg.str, clause)
+// OnConflictWhere adds ON CONFLICT target WHERE predicate, targeting a partial unique index.
+// The predicate must imply the index's own WHERE clause for Postgres to infer the index:
+//
+//	OnConflictWhere("(idempotency_key)", "completed_at IS NULL")
+func (pg PgString) OnConflictWhere(target, predicate string, args ...any) PgString {
+	if strings.TrimSpace(target) == "" {
+		return pg.fail(errors.New("OnConflictWhere requires conflict target columns"))
 	}
-	return pg
+	auditFragment("OnConflictWhere", predicate)
+	return pg.with(clause{kind: clauseOnConflict, sql: target + " WHERE " + predicate}).withArgs(args)
 }
 
 // DoNothing adds DO NOTHING to an ON CONFLICT clause
 func (pg PgString) DoNothing() PgString {
-	pg.str = fmt.Sprintf("%s DO NOTHING", pg.str)
-	return pg
+	return pg.with(clause{kind: clauseDoNothing})
 }
 
 // DoUpdate adds DO UPDATE SET to an ON CONFLICT clause
 func (pg PgString) DoUpdate() PgString {
-	pg.str = fmt.Sprintf("%s DO UPDATE", pg.str)
-	return pg
+	return pg.with(clause{kind: clauseDoUpdate})
 }
 
-func CreateTable(table string, obj any, options ...string) PgString {
-	val := reflect.ValueOf(obj)
+// CreateTable creates a CREATE TABLE statement with a column per mapped field of obj.
+// options are TableOption* strings and TableOptions values, merged and validated together.
+// When the table also needs DROP TABLE or enum types the statements are joined into one
+// string; use CreateTableScript to execute them one at a time.
+func CreateTable(table string, obj any, options ...any) PgString {
+	script := CreateTableScript(table, obj, options...)
+	if err := script.Err(); err != nil {
+		return PgString{}.fail(err)
+	}
+	return ddl(script.String(), table)
+}
 
-	// If pointer, get the underlying value
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
+// CreateTableFor is like CreateTable for the table obj is registered with or names with
+// TableName
+func CreateTableFor(obj any, options ...any) PgString {
+	m, err := modelOf(obj)
+	if err != nil {
+		return PgString{}.fail(fmt.Errorf("CreateTableFor: %w", err))
 	}
+	return CreateTable(m.table, obj, options...)
+}
+
+// CreateTableScript is like CreateTable but returns the enum types, DROP TABLE, CREATE
+// TABLE and CREATE INDEX as separate statements
+func CreateTableScript(table string, obj any, options ...any) Script {
+	t, err := tableDDLOf(table, obj, options)
+	if err != nil {
+		return NewScript(PgString{}.fail(err))
+	}
+	return NewScript(t.enums...).Add(t.drop...).Add(t.create).Add(t.indexes...)
+}
+
+// tableDDL holds the statements creating a table
+type tableDDL struct {
+	enums   []PgString
+	drop    []PgString
+	create  PgString
+	indexes []PgString
+	// references lists the tables the foreign keys point to
+	references  []string
+	foreignKeys []foreignKey
+}
+
+// foreignKey is a references= column of a generated table
+type foreignKey struct {
+	column   string
+	table    string   // referenced table
+	columns  []string // referenced columns
+	deferral Deferral
+}
+
+// tableDDLOf generates the statements creating table for the struct obj
+func tableDDLOf(table string, obj any, options []any) (tableDDL, error) {
+	val, ok := structValue(obj)
 
 	// Only struct types are supported
-	if val.Kind() != reflect.Struct {
-		return PgString{
-			str: fmt.Sprintf("-- Error: %v is not a struct", obj),
-		}
+	if !ok {
+		return tableDDL{}, fmt.Errorf("%v is not a struct", obj)
 	}
 
+	tableOptions, err := tableOptionsOf(options)
+	if err != nil {
+		return tableDDL{}, err
+	}
+
+	var t tableDDL
+	var indexed []string
+	var uniqueCI []string // unique on lower(column)
+
 	typ := val.Type()
 	var columns []string
 	var primaryKeys []string
 	var uniqueColumns []string
+	var enumTypes []*enumInfo
 
 	for i := 0; i < val.NumField(); i++ {
 		field := typ.Field(i)
@@ -539,201 +833,428 @@ func CreateTable(table string, obj any, options ...string) PgString {
 		}
 
 		// Determine column name (use db tag or field name)
-		columnName := field.Name
-		dbTag := field.Tag.Get("db")
-		if dbTag != "" && dbTag != "-" {
-			// Split tag to handle potential options
-			parts := strings.Split(dbTag, ",")
-			columnName = parts[0]
+		columnName, options := parseTag(field.Tag.Get("db"))
+		if columnName == "" {
+			columnName = field.Name
 		}
 
-		// Determine SQL type based on Go type
-		var sqlType string
-		fieldType := field.Type
-		isArray := false
-
-		// Check if it's a slice/array
-		if fieldType.Kind() == reflect.Slice {
-			isArray = true
-			fieldType = fieldType.Elem()
+		// Grouping fields are query results and nested structs live in other tables
+		if _, ok := optionValue(options, "grouping"); ok || hasOption(options, "nested") {
+			continue
 		}
 
-		switch fieldType.Kind() {
-		case reflect.String:
-			sqlType = "TEXT"
-			if isArray {
-				sqlType = "TEXT[]"
-			}
-		case reflect.Bool:
-			sqlType = "BOOLEAN"
-			if isArray {
-				sqlType = "BOOLEAN[]"
-			}
-		case reflect.Int, reflect.Int32:
-			sqlType = "INTEGER"
-			if isArray {
-				sqlType = "INTEGER[]"
-			}
-		case reflect.Int64:
-			sqlType = "BIGINT"
-			if isArray {
-				sqlType = "BIGINT[]"
-			}
-		case reflect.Float32:
-			sqlType = "REAL"
-			if isArray {
-				sqlType = "REAL[]"
-			}
-		case reflect.Float64:
-			sqlType = "DOUBLE PRECISION"
-			if isArray {
-				sqlType = "DOUBLE PRECISION[]"
-			}
-		default:
-			// Handle special types
-			switch fieldType.String() {
-			case "time.Time":
-				sqlType = "TIMESTAMP"
-				if isArray {
-					sqlType = "TIMESTAMP[]"
-				}
-			case "*time.Time":
-				sqlType = "TIMESTAMP"
-				if isArray {
-					sqlType = "TIMESTAMP[]"
-				}
+		// Determine SQL type based on Go type
+		sqlType, enum, isArray := columnType(field.Type, options)
+
+		// Case-insensitive uniqueness: a CITEXT column, or a unique index on lower(column)
+		ciMode, isCI := optionValue(options, "unique_ci")
+		if isCI || hasOption(options, "unique_ci") {
+			isCI = true
+			switch ciMode {
+			case "citext":
+				sqlType = "CITEXT"
+			case "", "lower":
+				uniqueCI = append(uniqueCI, columnName)
 			default:
-				sqlType = "TEXT" // fallback
-				if isArray {
-					sqlType = "TEXT[]"
-				}
+				return t, fmt.Errorf("%s: unique_ci must be citext or lower, not %q", columnName, ciMode)
 			}
 		}
+		if enum != nil && enum.typeName != "" && !slices.Contains(enumTypes, enum) {
+			enumTypes = append(enumTypes, enum)
+		}
 
 		// Check for constraints
-		columnDef := fmt.Sprintf("%s %s", columnName, sqlType)
+		column := quoteColumn(columnName)
+		columnDef := fmt.Sprintf("%s %s", column, sqlType)
 
 		// Check for primary key
-		if strings.Contains(dbTag, "primarykey") {
-			primaryKeys = append(primaryKeys, columnName)
+		if hasOption(options, "primarykey") {
+			primaryKeys = append(primaryKeys, column)
+		}
+
+		// Check for generated column
+		if expr, ok := optionValue(options, "generated"); ok {
+			columnDef += fmt.Sprintf(" GENERATED ALWAYS AS (%s) STORED", expr)
+		}
+
+		// Check for collation
+		if collation, ok := optionValue(options, "collate"); ok {
+			columnDef += " COLLATE " + quoteCollation(collation)
 		}
 
 		// Check for NOT NULL
-		if strings.Contains(dbTag, "notnull") {
+		if hasOption(options, "notnull") {
 			columnDef += " NOT NULL"
 		}
 
 		// Check for UNIQUE
-		if strings.Contains(dbTag, "unique") {
+		deferral := deferralOf(options)
+		if hasOption(options, "unique") || isCI && ciMode == "citext" {
 			uniqueColumns = append(uniqueColumns, columnName)
-			columnDef += " UNIQUE"
+			columnDef = withDeferral(columnDef+" UNIQUE", deferral)
+		}
+
+		// Check for foreign key
+		if ref, ok := optionValue(options, "references"); ok {
+			columnDef = withDeferral(columnDef+" REFERENCES "+ref, deferral)
+			refTable, refColumns, _ := strings.Cut(ref, "(")
+			refTable = strings.TrimSpace(refTable)
+			t.references = appendUnique(t.references, refTable)
+			t.foreignKeys = append(t.foreignKeys, foreignKey{column: columnName, table: refTable, columns: splitColumns(strings.TrimSuffix(refColumns, ")")), deferral: deferral})
+		}
+
+		// Check for index
+		if hasOption(options, "index") {
+			indexed = append(indexed, columnName)
+		}
+
+		// Check for enum values
+		if enum != nil && enum.typeName == "" {
+			if isArray {
+				columnDef += fmt.Sprintf(" CHECK (%s <@ ARRAY[%s])", column, enum.valueList())
+			} else {
+				columnDef += fmt.Sprintf(" CHECK (%s IN (%s))", column, enum.valueList())
+			}
 		}
 
 		columns = append(columns, columnDef)
 	}
 
-	// Construct CREATE TABLE statement with options
-	var createTableSQL strings.Builder
+	// Enum types must exist before the table referencing them
+	for _, enum := range enumTypes {
+		t.enums = append(t.enums, ddl(enum.createType(), ""))
+	}
 
 	// Handle table existence options
-	if len(options) > 0 {
-		switch options[0] {
-		case TableOptionIfNotExists:
-			createTableSQL.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n", table))
-		case TableOptionDropCascade:
-			createTableSQL.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE;\n", table))
-			createTableSQL.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", table))
-		case TableOptionDrop:
-			createTableSQL.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", table))
-			createTableSQL.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", table))
-		default:
-			createTableSQL.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", table))
+	if tableOptions.Drop {
+		drop := "DROP TABLE IF EXISTS " + table
+		if tableOptions.Cascade {
+			drop += " CASCADE"
 		}
-	} else {
-		createTableSQL.WriteString(fmt.Sprintf("CREATE TABLE %s (\n", table))
+		t.drop = append(t.drop, ddl(drop, table))
 	}
 
-	createTableSQL.WriteString("    " + strings.Join(columns, ",\n    "))
+	// Construct CREATE TABLE statement with options
+	var createTableSQL strings.Builder
+	createTableSQL.WriteString("CREATE ")
+	if tableOptions.Temporary {
+		createTableSQL.WriteString("TEMPORARY ")
+	} else if tableOptions.Unlogged {
+		createTableSQL.WriteString("UNLOGGED ")
+	}
+	createTableSQL.WriteString("TABLE ")
+	if tableOptions.IfNotExists {
+		createTableSQL.WriteString("IF NOT EXISTS ")
+	}
+	createTableSQL.WriteString(table + " (\n")
+
+	for i, column := range columns {
+		if i > 0 {
+			createTableSQL.WriteString(",\n")
+		}
+		createTableSQL.WriteString("    ")
+		createTableSQL.WriteString(column)
+	}
 
 	// Add primary key constraint
 	if len(primaryKeys) > 0 {
-		createTableSQL.WriteString(",\n    PRIMARY KEY (" + strings.Join(primaryKeys, ", ") + ")")
+		createTableSQL.WriteString(",\n    PRIMARY KEY (")
+		createTableSQL.WriteString(strings.Join(primaryKeys, ", "))
+		createTableSQL.WriteString(")")
+	}
+
+	// Add exclude constraints
+	for _, exclude := range tableOptions.Exclude {
+		createTableSQL.WriteString(",\n    ")
+		createTableSQL.WriteString(exclude.String())
 	}
 
 	createTableSQL.WriteString("\n)")
 
-	return PgString{
-		str: createTableSQL.String(),
+	if len(tableOptions.Inherits) > 0 {
+		createTableSQL.WriteString(" INHERITS (" + strings.Join(tableOptions.Inherits, ", ") + ")")
+	}
+	if tableOptions.PartitionBy != "" {
+		createTableSQL.WriteString(" PARTITION BY " + tableOptions.PartitionBy)
+	}
+	if storage := tableOptions.storageClause(); storage != "" {
+		createTableSQL.WriteString(" " + storage)
+	}
+	if tableOptions.Tablespace != "" {
+		createTableSQL.WriteString(" TABLESPACE " + tableOptions.Tablespace)
+	}
+
+	t.create = ddl(createTableSQL.String(), table)
+
+	// Index fields tagged index, named like Postgres names them: table_column_idx
+	_, unqualified, found := strings.Cut(table, ".")
+	if !found {
+		unqualified = table
+	}
+	for _, column := range indexed {
+		index := "CREATE INDEX "
+		if tableOptions.IfNotExists {
+			index += "IF NOT EXISTS "
+		}
+		index += unqualified + "_" + column + "_idx ON " + table + " (" + quoteColumn(column) + ")"
+		t.indexes = append(t.indexes, ddl(index, table))
+	}
+	for _, column := range uniqueCI {
+		index := "CREATE UNIQUE INDEX "
+		if tableOptions.IfNotExists {
+			index += "IF NOT EXISTS "
+		}
+		index += unqualified + "_" + column + "_lower_key ON " + table + " (lower(" + quoteColumn(column) + "))"
+		t.indexes = append(t.indexes, ddl(index, table))
+	}
+	return t, nil
+}
+
+// splitColumns splits a comma-separated column list
+func splitColumns(list string) []string {
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// columnType maps a struct field's Go type to a SQL column type. It also returns the enum
+// registered for the field's type, if any, and whether the column is an array.
+func columnType(fieldType reflect.Type, options []string) (string, *enumInfo, bool) {
+	var sqlType string
+	isArray := false
+
+	// Nullable pointer and Null fields use the type they hold
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if elem, ok := nullableElem(fieldType); ok {
+		fieldType = elem
+	}
+
+	// Check if it's a slice/array
+	if fieldType.Kind() == reflect.Slice {
+		isArray = true
+		fieldType = fieldType.Elem()
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		sqlType = "TEXT"
+		if isArray {
+			sqlType = "TEXT[]"
+		}
+	case reflect.Bool:
+		sqlType = "BOOLEAN"
+		if isArray {
+			sqlType = "BOOLEAN[]"
+		}
+	case reflect.Int, reflect.Int32:
+		sqlType = "INTEGER"
+		if isArray {
+			sqlType = "INTEGER[]"
+		}
+	case reflect.Int64:
+		sqlType = "BIGINT"
+		if isArray {
+			sqlType = "BIGINT[]"
+		}
+	case reflect.Float32:
+		sqlType = "REAL"
+		if isArray {
+			sqlType = "REAL[]"
+		}
+	case reflect.Float64:
+		sqlType = "DOUBLE PRECISION"
+		if isArray {
+			sqlType = "DOUBLE PRECISION[]"
+		}
+	default:
+		// Handle special types
+		switch fieldType.String() {
+		case "time.Time":
+			sqlType = "TIMESTAMP"
+			if isArray {
+				sqlType = "TIMESTAMP[]"
+			}
+		case "*time.Time":
+			sqlType = "TIMESTAMP"
+			if isArray {
+				sqlType = "TIMESTAMP[]"
+			}
+		default:
+			sqlType = "TEXT" // fallback
+			if pgType, ok := pgtypeColumn(fieldType); ok {
+				sqlType = pgType
+			}
+			if isArray {
+				sqlType += "[]"
+			}
+		}
+	}
+
+	// JSONB columns hold marshaled structs, maps and slices
+	if hasOption(options, "jsonb") {
+		return "JSONB", nil, false
+	}
+
+	if hasOption(options, "autouuid") {
+		return "UUID", nil, false
 	}
+
+	// Transformed columns hold what the transformer binds, e.g. encrypted bytes
+	if name := transformName(options); name != "" {
+		if t, err := transformerOf(name); err == nil && t.Type != "" {
+			return t.Type, nil, false
+		}
+		return "BYTEA", nil, false
+	}
+
+	// Registered enums become a Postgres enum type or a CHECK constraint
+	enum := enumOf(fieldType)
+	if enum != nil && enum.typeName != "" {
+		sqlType = enum.typeName
+		if isArray {
+			sqlType += "[]"
+		}
+	}
+
+	return sqlType, enum, isArray
 }
 
 // Left joins (add this to the existing methods)
-func (pg PgString) LeftJoin(table, condition string) PgString {
-	pg.str = fmt.Sprintf("%s LEFT JOIN %s ON %s", pg.str, table, condition)
-	return pg
+func (pg PgString) LeftJoin(table, condition string, args ...any) PgString {
+	return pg.Join("LEFT", table, condition, args...)
 }
 
 // Right joins
-func (pg PgString) RightJoin(table, condition string) PgString {
-	pg.str = fmt.Sprintf("%s RIGHT JOIN %s ON %s", pg.str, table, condition)
-	return pg
+func (pg PgString) RightJoin(table, condition string, args ...any) PgString {
+	return pg.Join("RIGHT", table, condition, args...)
 }
 
 // Full outer joins
-func (pg PgString) FullOuterJoin(table, condition string) PgString {
-	pg.str = fmt.Sprintf("%s FULL OUTER JOIN %s ON %s", pg.str, table, condition)
-	return pg
+func (pg PgString) FullOuterJoin(table, condition string, args ...any) PgString {
+	return pg.Join("FULL OUTER", table, condition, args...)
+}
+
+// NaturalJoin adds a NATURAL JOIN, matching the columns both tables share by name. Adding
+// a column to either table silently changes the join, so keep it to views designed for it.
+func (pg PgString) NaturalJoin(table string) PgString {
+	auditIdent("NaturalJoin", table)
+	return pg.with(clause{kind: clauseJoin, sql: "NATURAL JOIN " + table, table: tableName(table)})
+}
+
+// NaturalLeftJoin adds a NATURAL LEFT JOIN; see NaturalJoin
+func (pg PgString) NaturalLeftJoin(table string) PgString {
+	auditIdent("NaturalLeftJoin", table)
+	return pg.with(clause{kind: clauseJoin, sql: "NATURAL LEFT JOIN " + table, table: tableName(table)})
+}
+
+// JoinAs adds a JOIN of table under alias, for self-joins and repeated joins to the same
+// table, e.g. JoinAs("INNER", "employees", "manager", "manager.id = employees.manager_id")
+func (pg PgString) JoinAs(joinType, table, alias, condition string, args ...any) PgString {
+	auditIdent("JoinAs", alias)
+	return pg.Join(joinType, table+" AS "+alias, condition, args...)
+}
+
+// InnerJoinAs adds an INNER JOIN of table under alias
+func (pg PgString) InnerJoinAs(table, alias, condition string, args ...any) PgString {
+	return pg.JoinAs("INNER", table, alias, condition, args...)
+}
+
+// LeftJoinAs adds a LEFT JOIN of table under alias
+func (pg PgString) LeftJoinAs(table, alias, condition string, args ...any) PgString {
+	return pg.JoinAs("LEFT", table, alias, condition, args...)
+}
+
+// RightJoinAs adds a RIGHT JOIN of table under alias
+func (pg PgString) RightJoinAs(table, alias, condition string, args ...any) PgString {
+	return pg.JoinAs("RIGHT", table, alias, condition, args...)
+}
+
+// FullOuterJoinAs adds a FULL OUTER JOIN of table under alias
+func (pg PgString) FullOuterJoinAs(table, alias, condition string, args ...any) PgString {
+	return pg.JoinAs("FULL OUTER", table, alias, condition, args...)
 }
 
 // Distinct modifier for SELECT
 func (pg PgString) Distinct() PgString {
-	if strings.HasPrefix(pg.str, "SELECT") {
-		pg.str = strings.Replace(pg.str, "SELECT", "SELECT DISTINCT", 1)
+	if len(pg.clauses) > 0 && pg.clauses[0].kind == clauseSelect {
+		pg.clauses = slices.Clone(pg.clauses)
+		pg.clauses[0].kind = clauseSelectDistinct
 	}
 	return pg
 }
 
-// Like condition (for WHERE clauses)
-func (pg PgString) Like(column, pattern string) PgString {
-	condition := fmt.Sprintf("%s LIKE @%s_pattern", column, column)
-	pg.str = fmt.Sprintf("%s WHERE %s", pg.str, condition)
-	pg.namedArgs[column+"_pattern"] = pattern
-	return pg
-}
+// In condition. values must be a slice; when it would push the query past MaxParams
+// parameters the whole slice is bound as a single array with = ANY(@column_in) instead.
+// An empty slice matches no rows and renders FALSE. Placeholders of a qualified column
+// replace its dots with underscores, and a column used in several In calls gets a numbered
+// name per call (@id_in_0, then @id_in2_0), so the calls don't overwrite each other's args.
+func (pg PgString) In(column string, values any) PgString {
+	val := reflect.ValueOf(values)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return pg.fail(errors.New("In requires a slice of values"))
+	}
+	if val.Len() == 0 {
+		return pg.with(clause{kind: clauseWhere, sql: "FALSE"})
+	}
 
-// In condition
-func (pg PgString) In(column string, values []any) PgString {
-	placeholders := make([]string, len(values))
-	for i := range values {
-		placeholderKey := fmt.Sprintf("%s_in_%d", column, i)
-		placeholders[i] = fmt.Sprintf("@%s", placeholderKey)
-		pg.namedArgs[placeholderKey] = values[i]
+	base := argName(column) + "_in"
+	for n := 2; pg.hasArg(base) || pg.hasArg(base+"_0"); n++ {
+		base = argName(column) + "_in" + strconv.Itoa(n)
 	}
 
-	condition := fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
+	if pg.paramCount()+val.Len() > MaxParams {
+		condition := column + " = ANY(@" + base + ")"
+		return pg.with(clause{kind: clauseWhere, sql: condition}).withArg(base, values)
+	}
 
-	if strings.Contains(pg.str, " WHERE ") {
-		pg.str = fmt.Sprintf("%s AND %s", pg.str, condition)
-	} else {
-		pg.str = fmt.Sprintf("%s WHERE %s", pg.str, condition)
+	var condition strings.Builder
+	condition.WriteString(column)
+	condition.WriteString(" IN (")
+	pg.args = slices.Grow(slices.Clip(pg.args), val.Len())
+	for i := 0; i < val.Len(); i++ {
+		placeholderKey := base + "_" + strconv.Itoa(i)
+		if i > 0 {
+			condition.WriteString(", ")
+		}
+		condition.WriteByte('@')
+		condition.WriteString(placeholderKey)
+		pg.args = append(pg.args, namedArg{name: placeholderKey, value: val.Index(i).Interface()})
 	}
+	condition.WriteByte(')')
 
-	return pg
+	return pg.with(clause{kind: clauseWhere, sql: condition.String()})
 }
 
 // Between condition
 func (pg PgString) Between(column string, start, end any) PgString {
-	condition := fmt.Sprintf("%s BETWEEN @%s_start AND @%s_end", column, column, column)
-	pg.str = fmt.Sprintf("%s WHERE %s", pg.str, condition)
-	pg.namedArgs[column+"_start"] = start
-	pg.namedArgs[column+"_end"] = end
-	return pg
+	name := argName(column)
+	condition := column + " BETWEEN @" + name + "_start AND @" + name + "_end"
+	return pg.with(clause{kind: clauseWhere, sql: condition}).
+		withArg(name+"_start", start).
+		withArg(name+"_end", end)
+}
+
+// WhereBoolFilter adds column = TRUE or column = FALSE for an optional boolean filter, and
+// nothing when v is nil
+func (pg PgString) WhereBoolFilter(column string, v *bool) PgString {
+	if v == nil {
+		return pg
+	}
+	auditIdent("WhereBoolFilter", column)
+	if *v {
+		return pg.with(clause{kind: clauseWhere, sql: column + " = TRUE"})
+	}
+	return pg.with(clause{kind: clauseWhere, sql: column + " = FALSE"})
 }
 
 // Raw SQL method for complex queries
 func RawSQL(query string) PgString {
-	return PgString{
-		str:       query,
-		namedArgs: map[string]any{},
-	}
+	auditFragment("RawSQL", query)
+	return PgString{}.with(clause{kind: clauseRaw, sql: query})
 }
@@ -0,0 +1,105 @@
+package pgstring_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+type product struct {
+	ID    int     `db:"id,primarykey"`
+	Name  string  `db:"name"`
+	Price float64 `db:"price"`
+}
+
+// assertSQL builds pg and checks its SQL and named args
+func assertSQL(t *testing.T, pg pgstring.PgString, wantSQL string, wantArgs map[string]any) {
+	t.Helper()
+	sql, args, err := pg.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if sql != wantSQL {
+		t.Errorf("SQL\n got: %s\nwant: %s", sql, wantSQL)
+	}
+	if len(args) == 0 && len(wantArgs) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args\n got: %v\nwant: %v", args, wantArgs)
+	}
+}
+
+// assertErr checks that building pg fails with an error containing want
+func assertErr(t *testing.T, pg pgstring.PgString, want string) {
+	t.Helper()
+	_, _, err := pg.Build()
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Build error = %v, want one containing %q", err, want)
+	}
+}
+
+func TestValuesBulk(t *testing.T) {
+	rows := []product{{ID: 1, Name: "a", Price: 1.5}, {ID: 2, Name: "b", Price: 2}}
+	assertSQL(t, pgstring.InsertInto("products").Obj(product{}).ValuesBulk(rows),
+		"INSERT INTO products (id, name, price) VALUES (@id_0, @name_0, @price_0), (@id_1, @name_1, @price_1)",
+		map[string]any{"id_0": 1, "name_0": "a", "price_0": 1.5, "id_1": 2, "name_1": "b", "price_1": 2.0})
+}
+
+func TestValuesBulkEmpty(t *testing.T) {
+	assertErr(t, pgstring.InsertInto("products").Obj(product{}).ValuesBulk([]product{}), "no rows")
+}
+
+func TestChunks(t *testing.T) {
+	rows := make([]product, 30000)
+	chunks := pgstring.InsertInto("products").Obj(product{}).ValuesBulk(rows).OnConflict("").DoNothing().Chunks()
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	total := 0
+	for _, chunk := range chunks {
+		n := len(chunk.NamedArgs())
+		if n > pgstring.MaxParams {
+			t.Errorf("chunk binds %d params", n)
+		}
+		if !strings.HasSuffix(chunk.String(), " ON CONFLICT DO NOTHING") {
+			t.Errorf("chunk lost its ON CONFLICT clause")
+		}
+		total += n
+	}
+	if total != 3*len(rows) {
+		t.Errorf("chunks bind %d params, want %d", total, 3*len(rows))
+	}
+}
+
+func TestIn(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").In("id", []int{1, 2}),
+		"SELECT * FROM users WHERE id IN (@id_in_0, @id_in_1)",
+		map[string]any{"id_in_0": 1, "id_in_1": 2})
+}
+
+func TestInEmpty(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").In("id", []int{}),
+		"SELECT * FROM users WHERE FALSE", nil)
+}
+
+func TestInQualified(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users u").In("u.id", []int{7}),
+		"SELECT * FROM users u WHERE u.id IN (@u_id_in_0)",
+		map[string]any{"u_id_in_0": 7})
+}
+
+func TestInTwice(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").In("id", []int{1, 2}).In("id", []int{3}),
+		"SELECT * FROM users WHERE (id IN (@id_in_0, @id_in_1)) AND (id IN (@id_in2_0))",
+		map[string]any{"id_in_0": 1, "id_in_1": 2, "id_in2_0": 3})
+}
+
+func TestInLarge(t *testing.T) {
+	ids := make([]int, pgstring.MaxParams+1)
+	assertSQL(t, pgstring.Select("*").From("users").In("id", ids),
+		"SELECT * FROM users WHERE id = ANY(@id_in)",
+		map[string]any{"id_in": ids})
+}
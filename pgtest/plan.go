@@ -0,0 +1,126 @@
+// Package pgtest provides helpers for testing queries against a real database.
+package pgtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/oliverpaddock/pgstring"
+	"github.com/oliverpaddock/pgstring/pgexec"
+)
+
+// Plan is the output of EXPLAIN (FORMAT JSON) for a query
+type Plan struct {
+	Root Node `json:"Plan"`
+}
+
+// Node is a node of a query plan
+type Node struct {
+	NodeType     string  `json:"Node Type"`
+	RelationName string  `json:"Relation Name"`
+	Alias        string  `json:"Alias"`
+	IndexName    string  `json:"Index Name"`
+	StartupCost  float64 `json:"Startup Cost"`
+	TotalCost    float64 `json:"Total Cost"`
+	PlanRows     float64 `json:"Plan Rows"`
+	Plans        []Node  `json:"Plans"`
+}
+
+// Check inspects a plan and returns an error describing what is wrong with it
+type Check func(plan *Plan) error
+
+// Explain runs EXPLAIN (FORMAT JSON) for pg and parses the plan. The query is planned but not executed.
+func Explain(ctx context.Context, db pgexec.Querier, pg pgstring.PgString) (*Plan, error) {
+	sql, namedArgs, err := pg.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	var args []any
+	if len(namedArgs) > 0 {
+		args = []any{pgx.NamedArgs(namedArgs)}
+	}
+
+	var output []byte
+	if err := db.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+sql, args...).Scan(&output); err != nil {
+		return nil, err
+	}
+
+	var plans []Plan
+	if err := json.Unmarshal(output, &plans); err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("EXPLAIN returned no plan")
+	}
+	return &plans[0], nil
+}
+
+// AssertPlan explains pg and fails t for every check the plan doesn't pass
+func AssertPlan(t testing.TB, db pgexec.Querier, pg pgstring.PgString, checks ...Check) *Plan {
+	t.Helper()
+
+	plan, err := Explain(context.Background(), db, pg)
+	if err != nil {
+		t.Fatalf("explain %s: %v", pg, err)
+		return nil
+	}
+
+	for _, check := range checks {
+		if err := check(plan); err != nil {
+			t.Errorf("plan of %s: %v", pg, err)
+		}
+	}
+	return plan
+}
+
+// Nodes returns every node of the plan in depth-first order
+func (p *Plan) Nodes() []Node {
+	var nodes []Node
+	var walk func(n Node)
+	walk = func(n Node) {
+		nodes = append(nodes, n)
+		for _, child := range n.Plans {
+			walk(child)
+		}
+	}
+	walk(p.Root)
+	return nodes
+}
+
+// UsesIndex requires the plan to scan the named index
+func UsesIndex(name string) Check {
+	return func(plan *Plan) error {
+		for _, n := range plan.Nodes() {
+			if n.IndexName == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("index %s is not used", name)
+	}
+}
+
+// NoSeqScan requires the plan not to sequentially scan table
+func NoSeqScan(table string) Check {
+	return func(plan *Plan) error {
+		for _, n := range plan.Nodes() {
+			if n.NodeType == "Seq Scan" && n.RelationName == table {
+				return fmt.Errorf("sequential scan on %s", table)
+			}
+		}
+		return nil
+	}
+}
+
+// MaxCost requires the estimated total cost of the plan not to exceed cost
+func MaxCost(cost float64) Check {
+	return func(plan *Plan) error {
+		if plan.Root.TotalCost > cost {
+			return fmt.Errorf("total cost %.2f exceeds %.2f", plan.Root.TotalCost, cost)
+		}
+		return nil
+	}
+}
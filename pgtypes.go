@@ -0,0 +1,42 @@
+package pgstring
+
+import (
+	"reflect"
+	"strings"
+)
+
+// pgtypeColumns maps the types of pgx's pgtype package (and the v4 github.com/jackc/pgtype
+// module) to the column type they encode. They're commonly used for nullable columns.
+var pgtypeColumns = map[string]string{
+	"Bool":        "BOOLEAN",
+	"Bytea":       "BYTEA",
+	"Date":        "DATE",
+	"Float4":      "REAL",
+	"Float8":      "DOUBLE PRECISION",
+	"Hstore":      "HSTORE",
+	"Inet":        "INET",
+	"Int2":        "SMALLINT",
+	"Int4":        "INTEGER",
+	"Int8":        "BIGINT",
+	"Interval":    "INTERVAL",
+	"JSON":        "JSON",
+	"JSONB":       "JSONB",
+	"Numeric":     "NUMERIC",
+	"Point":       "POINT",
+	"Text":        "TEXT",
+	"Time":        "TIME",
+	"Timestamp":   "TIMESTAMP",
+	"Timestamptz": "TIMESTAMPTZ",
+	"UUID":        "UUID",
+	"Varchar":     "VARCHAR",
+}
+
+// pgtypeColumn returns the column type of a pgtype type
+func pgtypeColumn(typ reflect.Type) (string, bool) {
+	path := typ.PkgPath()
+	if !strings.HasPrefix(path, "github.com/jackc/pgx/v5/pgtype") && path != "github.com/jackc/pgtype" {
+		return "", false
+	}
+	sqlType, ok := pgtypeColumns[typ.Name()]
+	return sqlType, ok
+}
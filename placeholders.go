@@ -0,0 +1,137 @@
+package pgstring
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// placeholders returns the named placeholders (@name) referenced by sql, in order of first
+// appearance. Placeholders inside string literals, quoted identifiers and comments are ignored.
+func placeholders(sql string) []string {
+	var names []string
+	seen := map[string]bool{}
+	scanPlaceholders(sql, func(start, end int) {
+		name := sql[start+1 : end]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+// Placeholders returns the named placeholders (@name) referenced by arbitrary SQL, such as
+// RawSQL text, in order of first appearance. @ inside string literals, dollar-quoted
+// strings, quoted identifiers and comments is ignored, as are operators like @> and @@.
+func Placeholders(sql string) []string {
+	return placeholders(sql)
+}
+
+// BindNamed rewrites the @name placeholders of sql as positional $n parameters and returns
+// the args in parameter order, failing when a placeholder has no arg. Repeated placeholders
+// share a parameter; args that sql doesn't reference are ignored.
+func BindNamed(sql string, args map[string]any) (string, []any, error) {
+	var b strings.Builder
+	var positional []any
+	var missing []string
+	params := map[string]int{}
+	last := 0
+	scanPlaceholders(sql, func(start, end int) {
+		name := sql[start+1 : end]
+		n, ok := params[name]
+		if !ok {
+			value, found := args[name]
+			if !found {
+				if !slices.Contains(missing, name) {
+					missing = append(missing, name)
+				}
+				return
+			}
+			positional = append(positional, value)
+			n = len(positional)
+			params[name] = n
+		}
+		b.WriteString(sql[last:start])
+		b.WriteString("$" + strconv.Itoa(n))
+		last = end
+	})
+	if len(missing) > 0 {
+		return "", nil, fmt.Errorf("missing args for placeholders: %s", strings.Join(missing, ", "))
+	}
+	b.WriteString(sql[last:])
+	return b.String(), positional, nil
+}
+
+// scanPlaceholders calls fn with the bounds of each @name placeholder in sql, skipping
+// string literals, dollar-quoted strings, quoted identifiers and comments
+func scanPlaceholders(sql string, fn func(start, end int)) {
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"':
+			i = skipQuoted(sql, i, c)
+		case c == '$' && (i == 0 || !isIdentChar(sql[i-1])):
+			i = skipDollarQuoted(sql, i)
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case c == '@' && i+1 < len(sql) && isIdentStart(sql[i+1]) && (i == 0 || sql[i-1] != '@' && !isIdentChar(sql[i-1])):
+			end := i + 2
+			for end < len(sql) && isIdentChar(sql[end]) {
+				end++
+			}
+			fn(i, end)
+			i = end - 1
+		}
+	}
+}
+
+// skipQuoted returns the index of the quote closing the literal that starts at start.
+// Doubled quotes inside the literal are treated as escapes.
+func skipQuoted(sql string, start int, quote byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] == quote {
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql)
+}
+
+// skipDollarQuoted returns the index of the last character of the dollar-quoted string
+// ($$...$$ or $tag$...$tag$) that starts at start, or start when it doesn't open one
+func skipDollarQuoted(sql string, start int) int {
+	end := start + 1
+	for end < len(sql) && isIdentChar(sql[end]) && (end > start+1 || isIdentStart(sql[end])) {
+		end++
+	}
+	if end >= len(sql) || sql[end] != '$' {
+		return start
+	}
+	tag := sql[start : end+1]
+	if close := strings.Index(sql[end+1:], tag); close >= 0 {
+		return end + close + len(tag)
+	}
+	return len(sql)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
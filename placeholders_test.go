@@ -0,0 +1,44 @@
+package pgstring_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestPlaceholders(t *testing.T) {
+	sql := "SELECT '@skip', \"@skip\", $$@skip$$, $q$ @skip $q$, a::text, b @> c, d @@ e /* @skip */ " +
+		"FROM t WHERE id = @id AND x = @x2 AND y = @id -- @skip"
+	if got, want := pgstring.Placeholders(sql), []string{"id", "x2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPlaceholdersDollarParams(t *testing.T) {
+	// $1 is a positional parameter, not the start of a dollar-quoted string
+	if got, want := pgstring.Placeholders("SELECT $1, @a, $2"), []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestBindNamed(t *testing.T) {
+	sql, args, err := pgstring.BindNamed("SELECT * FROM t WHERE a = @a AND b = @b OR a = @a AND c = '@c'",
+		map[string]any{"a": 1, "b": "x", "unused": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT * FROM t WHERE a = $1 AND b = $2 OR a = $1 AND c = '@c'"; sql != want {
+		t.Errorf("SQL\n got: %s\nwant: %s", sql, want)
+	}
+	if want := []any{1, "x"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("got args %v, want %v", args, want)
+	}
+}
+
+func TestBindNamedMissing(t *testing.T) {
+	_, _, err := pgstring.BindNamed("SELECT @a, @b, @a", map[string]any{})
+	if err == nil || err.Error() != "missing args for placeholders: a, b" {
+		t.Errorf("got %v", err)
+	}
+}
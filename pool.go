@@ -0,0 +1,53 @@
+package pgstring
+
+import (
+	"bufio"
+	"sync"
+)
+
+// maxPooledBuffer is the largest buffer returned to the pool; buffers grown by huge
+// generated scripts are left to the garbage collector instead of being kept around
+const maxPooledBuffer = 64 << 10
+
+// sqlBuffer is a reusable byte buffer queries are rendered into
+type sqlBuffer struct {
+	b []byte
+}
+
+func (buf *sqlBuffer) WriteString(s string) (int, error) {
+	buf.b = append(buf.b, s...)
+	return len(s), nil
+}
+
+func (buf *sqlBuffer) WriteByte(c byte) error {
+	buf.b = append(buf.b, c)
+	return nil
+}
+
+// bufferPool holds render buffers so building a query allocates only its final string
+var bufferPool = sync.Pool{
+	New: func() any {
+		return &sqlBuffer{b: make([]byte, 0, 1024)}
+	},
+}
+
+// writerPool holds the buffered writers used by WriteTo
+var writerPool = sync.Pool{
+	New: func() any {
+		return bufio.NewWriter(nil)
+	},
+}
+
+// getBuffer takes an empty render buffer from the pool
+func getBuffer() *sqlBuffer {
+	return bufferPool.Get().(*sqlBuffer)
+}
+
+// putBuffer returns a render buffer to the pool
+func putBuffer(buf *sqlBuffer) {
+	if cap(buf.b) > maxPooledBuffer {
+		return
+	}
+	buf.b = buf.b[:0]
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,189 @@
+package pgstring
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// model is a struct type registered with Register
+type model struct {
+	table string
+	info  *structInfo
+	// keys lists the primarykey columns, in field order
+	keys []string
+}
+
+// models maps registered struct types to their model
+var models sync.Map // reflect.Type to *model
+
+// Tabler is implemented by models that name their own table, so SelectByPK, InsertObj,
+// Upsert, CreateTableFor and CreateSchemaFor need no Register call for them. TableName is
+// called on the zero value when the model is a nil pointer.
+type Tabler interface {
+	TableName() string
+}
+
+// Register maps the struct type of obj (a struct or a pointer to one) to table, so
+// SelectByPK, InsertObj and Upsert can resolve the table from the type. The column
+// mapping is cached on registration; registering a type again replaces its table, and
+// a registered table takes precedence over TableName. Register panics if obj isn't a
+// struct, to catch mistakes at init.
+//
+//	pgstring.Register("users", User{})
+func Register(table string, obj any) {
+	typ := modelType(obj)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("pgstring: Register(%q): %v", table, errNotStruct))
+	}
+	models.Store(typ, newModel(table, typ))
+}
+
+// newModel returns the model of struct type typ stored in table
+func newModel(table string, typ reflect.Type) *model {
+	info := structInfoOf(typ)
+	m := &model{table: table, info: info}
+	for _, field := range info.fields {
+		if hasOption(field.options, "primarykey") {
+			m.keys = append(m.keys, field.name)
+		}
+	}
+	return m
+}
+
+// TableOf returns the table the type of obj was registered with, or its TableName
+func TableOf(obj any) (string, bool) {
+	m, err := modelOf(obj)
+	if err != nil {
+		return "", false
+	}
+	return m.table, true
+}
+
+// modelType returns the type of obj, dereferencing pointer types so nil pointers resolve too
+func modelType(obj any) reflect.Type {
+	typ := reflect.TypeOf(obj)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// modelOf returns the registered model of obj's type, or one for its TableName
+func modelOf(obj any) (*model, error) {
+	typ := modelType(obj)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errNotStruct
+	}
+	if m, ok := models.Load(typ); ok {
+		return m.(*model), nil
+	}
+	if t, ok := tablerOf(obj, typ); ok {
+		if table := t.TableName(); table != "" {
+			return newModel(table, typ), nil
+		}
+	}
+	return nil, fmt.Errorf("%s isn't registered, call Register or implement Tabler", typ)
+}
+
+// tablerOf returns obj as a Tabler, using a zero value of typ for nil pointers and for
+// TableName methods with a pointer receiver
+func tablerOf(obj any, typ reflect.Type) (Tabler, bool) {
+	if val := reflect.ValueOf(obj); val.Kind() != reflect.Ptr || !val.IsNil() {
+		if t, ok := obj.(Tabler); ok {
+			return t, true
+		}
+	}
+	t, ok := reflect.New(typ).Interface().(Tabler)
+	return t, ok
+}
+
+// primaryKeys returns the primary key columns of m, failing when it has none
+func (m *model) primaryKeys() ([]string, error) {
+	if len(m.keys) == 0 {
+		return nil, errors.New(m.table + " has no primarykey field")
+	}
+	return m.keys, nil
+}
+
+// SelectByPK selects the columns of obj from its registered table where the primary key
+// columns equal key, given in field order. Without key the model's own key fields are used:
+//
+//	pgstring.SelectByPK(User{}, 42)
+//	pgstring.SelectByPK(User{ID: 42})
+func SelectByPK(obj any, key ...any) PgString {
+	m, err := modelOf(obj)
+	if err != nil {
+		return PgString{}.fail(fmt.Errorf("SelectByPK: %w", err))
+	}
+	keys, err := m.primaryKeys()
+	if err != nil {
+		return PgString{}.fail(fmt.Errorf("SelectByPK: %w", err))
+	}
+	if len(key) != 0 && len(key) != len(keys) {
+		return PgString{}.fail(fmt.Errorf("SelectByPK: %s has %d primary key columns, got %d values", m.table, len(keys), len(key)))
+	}
+
+	if _, ok := structValue(obj); !ok {
+		if len(key) == 0 {
+			return PgString{}.fail(errors.New("SelectByPK: a nil model needs key values"))
+		}
+		obj = reflect.Zero(modelType(obj)).Interface()
+	}
+
+	pg := Select(obj).From(m.table)
+	conditions := make([]string, len(keys))
+	for i, column := range keys {
+		conditions[i] = quoteColumn(column) + " = @" + column
+		if len(key) != 0 {
+			pg = pg.withArg(column, key[i])
+		}
+	}
+	return pg.with(clause{kind: clauseWhere, sql: strings.Join(conditions, " AND ")})
+}
+
+// InsertObj inserts obj into its registered or TableName table, like
+// InsertInto(table).Obj(obj).Values(obj)
+func InsertObj(obj any) PgString {
+	m, err := modelOf(obj)
+	if err != nil {
+		return PgString{}.fail(fmt.Errorf("InsertObj: %w", err))
+	}
+	return InsertInto(m.table).Obj(obj).Values(obj)
+}
+
+// Upsert inserts obj into its registered or TableName table, or updates the existing row
+// with the same primary key from the inserted values: every writable column but the key
+// and createdat fields is assigned EXCLUDED.column. A model with nothing else to update
+// gets DO NOTHING.
+func Upsert(obj any) PgString {
+	m, err := modelOf(obj)
+	if err != nil {
+		return PgString{}.fail(fmt.Errorf("Upsert: %w", err))
+	}
+	keys, err := m.primaryKeys()
+	if err != nil {
+		return PgString{}.fail(fmt.Errorf("Upsert: %w", err))
+	}
+
+	columns := make([]string, len(keys))
+	for i, key := range keys {
+		columns[i] = quoteColumn(key)
+	}
+	pg := InsertInto(m.table).Obj(obj).Values(obj).OnConflict("(" + strings.Join(columns, ", ") + ")")
+
+	var setters []string
+	for _, field := range m.info.fields {
+		if !slices.Contains(m.info.writable, field.name) || slices.Contains(keys, field.name) || field.auto == autoCreated {
+			continue
+		}
+		setters = append(setters, quoteColumn(field.name)+" = EXCLUDED."+quoteColumn(field.name))
+	}
+	if len(setters) == 0 {
+		return pg.DoNothing()
+	}
+	return pg.DoUpdate().with(clause{kind: clauseSet, fields: setters})
+}
@@ -0,0 +1,54 @@
+package pgstring_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+type member struct {
+	ID        int       `db:"id,primarykey"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at,createdat"`
+	UpdatedAt time.Time `db:"updated_at,updatedat"`
+}
+
+type tag struct {
+	Name string `db:"name,primarykey"`
+}
+
+func (tag) TableName() string { return "tags" }
+
+func init() {
+	pgstring.Register("members", member{})
+}
+
+func TestSelectByPK(t *testing.T) {
+	assertSQL(t, pgstring.SelectByPK(member{}, 42),
+		"SELECT id, name, created_at, updated_at FROM members WHERE id = @id",
+		map[string]any{"id": 42, "name": "", "created_at": time.Time{}, "updated_at": time.Time{}})
+}
+
+func TestInsertObj(t *testing.T) {
+	assertSQL(t, pgstring.InsertObj(tag{Name: "go"}),
+		"INSERT INTO tags (name) VALUES (@name)",
+		map[string]any{"name": "go"})
+}
+
+func TestUpsert(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pgstring.SetClock(pgstring.FixedClock(now))
+	defer pgstring.SetClock(nil)
+
+	assertSQL(t, pgstring.Upsert(member{ID: 1, Name: "Ada"}),
+		"INSERT INTO members (id, name, created_at, updated_at) VALUES (@id, @name, @created_at, @updated_at) "+
+			"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at",
+		map[string]any{"id": 1, "name": "Ada", "created_at": now, "updated_at": now})
+}
+
+func TestUpsertOnlyKey(t *testing.T) {
+	assertSQL(t, pgstring.Upsert(tag{Name: "go"}),
+		"INSERT INTO tags (name) VALUES (@name) ON CONFLICT (name) DO NOTHING",
+		map[string]any{"name": "go"})
+}
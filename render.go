@@ -0,0 +1,332 @@
+package pgstring
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// clauseKind identifies a clause in a query's clause list
+type clauseKind int
+
+const (
+	clauseRaw clauseKind = iota
+	clauseSelect
+	clauseSelectDistinct
+	clauseInsert
+	clauseUpdate
+	clauseDelete
+	clauseColumns
+	clauseValues
+	clauseFrom
+	clauseSet
+	clauseJoin
+	clauseWhere
+	clauseGroupBy
+	clauseHaving
+	clauseOrderBy
+	clauseLimit
+	clauseOffset
+	clauseReturning
+	clauseOnConflict
+	clauseDoNothing
+	clauseDoUpdate
+	clauseAlterTable
+	clauseAlterAction
+	clauseInto
+	clauseTableSample
+)
+
+// clauseKeywords holds the keyword each clause kind is rendered with
+var clauseKeywords = [...]string{
+	clauseSelect:         "SELECT",
+	clauseSelectDistinct: "SELECT DISTINCT",
+	clauseInsert:         "INSERT INTO",
+	clauseUpdate:         "UPDATE",
+	clauseDelete:         "DELETE",
+	clauseValues:         "VALUES",
+	clauseFrom:           "FROM",
+	clauseSet:            "SET",
+	clauseGroupBy:        "GROUP BY",
+	clauseHaving:         "HAVING",
+	clauseOrderBy:        "ORDER BY",
+	clauseLimit:          "LIMIT",
+	clauseOffset:         "OFFSET",
+	clauseReturning:      "RETURNING",
+	clauseOnConflict:     "ON CONFLICT",
+	clauseDoNothing:      "DO NOTHING",
+	clauseDoUpdate:       "DO UPDATE",
+	clauseAlterTable:     "ALTER TABLE",
+	clauseAlterAction:    "",
+	clauseInto:           "INTO",
+	clauseTableSample:    "TABLESAMPLE",
+}
+
+// clause is a single piece of a query. Most clauses are a keyword followed by sql;
+// column lists and VALUES clauses are rendered from fields (and rows for bulk inserts).
+type clause struct {
+	kind   clauseKind
+	sql    string
+	fields []string
+	rows   [][]any
+	// table is the table the clause reads, or writes when writes is set
+	table  string
+	writes bool
+	// sensitive lists the columns of rows whose values are redacted
+	sensitive []string
+}
+
+// namedArg is a single named argument bound to a query
+type namedArg struct {
+	name  string
+	value any
+	// sensitive args are redacted from DebugString and RedactedArgs
+	sensitive bool
+}
+
+// sqlWriter is implemented by the pooled render buffer, strings.Builder and bufio.Writer
+type sqlWriter interface {
+	io.StringWriter
+	io.ByteWriter
+}
+
+// singleClauses are the clauses a query has at most one of; adding another replaces it
+var singleClauses = map[clauseKind]bool{
+	clauseLimit:  true,
+	clauseOffset: true,
+}
+
+// with returns pg with c appended. The clause list is clipped first so queries branched
+// from a shared base never write into each other's backing array.
+func (pg PgString) with(c clause) PgString {
+	if singleClauses[c.kind] {
+		if i := slices.IndexFunc(pg.clauses, func(existing clause) bool { return existing.kind == c.kind }); i >= 0 {
+			pg.clauses = slices.Clone(pg.clauses)
+			pg.clauses[i] = c
+			return pg
+		}
+	}
+	if pg.ordered {
+		if pos, ok := pg.orderedPos(c.kind); ok {
+			pg.clauses = slices.Insert(slices.Clone(pg.clauses), pos, c)
+			return pg
+		}
+	}
+	pg.clauses = append(slices.Clip(pg.clauses), c)
+	return pg
+}
+
+// withArg returns pg with a single named argument added
+func (pg PgString) withArg(name string, value any) PgString {
+	pg.args = append(slices.Clip(pg.args), namedArg{name: name, value: value})
+	return pg
+}
+
+// hasArg reports whether pg already binds an arg called name
+func (pg PgString) hasArg(name string) bool {
+	return slices.ContainsFunc(pg.args, func(arg namedArg) bool { return arg.name == name })
+}
+
+// argName turns a possibly qualified column into a placeholder name, e.g. u.id to u_id
+func argName(column string) string {
+	return strings.ReplaceAll(column, ".", "_")
+}
+
+// withArgs merges the optional args of a condition method (a map or a struct) into the query
+func (pg PgString) withArgs(args []any) PgString {
+	if len(args) != 1 {
+		return pg
+	}
+
+	if obj, ok := args[0].(map[string]any); ok {
+		pg.args = slices.Grow(slices.Clip(pg.args), len(obj))
+		for _, k := range slices.Sorted(maps.Keys(obj)) {
+			pg.args = append(pg.args, namedArg{name: k, value: obj[k]})
+		}
+	} else {
+		// Extract named args from struct
+		pg = pg.withStructArgs(args[0])
+	}
+
+	return pg
+}
+
+// paramCount returns the number of parameters the query binds
+func (pg PgString) paramCount() int {
+	n := len(pg.args)
+	for _, c := range pg.clauses {
+		n += len(c.rows) * len(c.fields)
+	}
+	return n
+}
+
+// render assembles the query text from its clauses in a single pass
+func (pg PgString) render() string {
+	if pg.err != nil {
+		return "Error: " + pg.err.Error()
+	}
+
+	buf := getBuffer()
+	pg.writeSQL(buf)
+	sql := string(buf.b)
+	putBuffer(buf)
+	return sql
+}
+
+// mergedKinds are the clause kinds whose consecutive clauses are merged into one list
+var mergedKinds = map[clauseKind]bool{
+	clauseOrderBy:     true,
+	clauseSet:         true,
+	clauseReturning:   true,
+	clauseAlterAction: true,
+}
+
+// writeSQL writes the query text to w. Consecutive WHERE and HAVING conditions are joined with
+// AND, each in parentheses so an OR in one doesn't regroup the others; consecutive ORDER BY
+// items, SET assignments, RETURNING fields and ALTER TABLE actions are merged into one list.
+func (pg PgString) writeSQL(w sqlWriter) {
+	for i, c := range pg.clauses {
+		if mergedKinds[c.kind] && i > 0 && pg.clauses[i-1].kind == c.kind {
+			w.WriteString(", ")
+			if c.fields != nil {
+				writeColumns(w, c.fields)
+			} else {
+				w.WriteString(c.sql)
+			}
+			continue
+		}
+
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+
+		if c.kind == clauseWhere || c.kind == clauseHaving {
+			first := i == 0 || pg.clauses[i-1].kind != c.kind
+			if !first {
+				w.WriteString("AND ")
+			} else if c.kind == clauseWhere {
+				w.WriteString("WHERE ")
+			} else {
+				w.WriteString("HAVING ")
+			}
+			last := i == len(pg.clauses)-1 || pg.clauses[i+1].kind != c.kind
+			if first && last || enclosed(c.sql) {
+				w.WriteString(c.sql)
+			} else {
+				w.WriteByte('(')
+				w.WriteString(c.sql)
+				w.WriteByte(')')
+			}
+			continue
+		}
+
+		switch c.kind {
+		case clauseColumns:
+			w.WriteByte('(')
+			writeColumns(w, c.fields)
+			w.WriteByte(')')
+		case clauseValues:
+			if c.rows != nil {
+				writeBulkValues(w, c.fields, len(c.rows))
+			} else {
+				w.WriteString("VALUES (")
+				writeList(w, "@", c.fields)
+				w.WriteByte(')')
+			}
+		default:
+			keyword := clauseKeywords[c.kind]
+			w.WriteString(keyword)
+			if keyword != "" && (c.sql != "" || c.fields != nil) {
+				w.WriteByte(' ')
+			}
+			if c.fields != nil {
+				writeColumns(w, c.fields)
+			} else {
+				w.WriteString(c.sql)
+			}
+		}
+	}
+}
+
+// enclosed reports whether a condition is wrapped in a single pair of parentheses, as in
+// (a OR b) but not (a) OR (b)
+func enclosed(condition string) bool {
+	if len(condition) < 2 || condition[0] != '(' || condition[len(condition)-1] != ')' {
+		return false
+	}
+	depth := 0
+	for i := 0; i < len(condition); i++ {
+		switch c := condition[i]; c {
+		case '\'', '"':
+			i = skipQuoted(condition, i, c)
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i < len(condition)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// writeList writes items separated by commas, each preceded by prefix
+func writeList(w sqlWriter, prefix string, items []string) {
+	for i, item := range items {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		w.WriteString(prefix)
+		w.WriteString(item)
+	}
+}
+
+// namedArgs collects the query's named arguments, including bulk VALUES rows. Null values
+// are replaced by what they bind.
+func (pg PgString) namedArgs() map[string]any {
+	result := make(map[string]any, pg.paramCount())
+	for _, arg := range pg.args {
+		result[arg.name] = bindable(arg.value)
+	}
+	for _, c := range pg.clauses {
+		for i, row := range c.rows {
+			for j, field := range c.fields {
+				result[bulkArgName(field, i)] = bindable(row[j])
+			}
+		}
+	}
+	return result
+}
+
+// countWriter counts the bytes written through it
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// WriteTo streams the query text to w, implementing io.WriterTo. Large generated scripts
+// (bulk DDL, seed data) are written clause by clause without assembling the full statement.
+func (pg PgString) WriteTo(w io.Writer) (int64, error) {
+	if pg.err != nil {
+		n, err := io.WriteString(w, pg.render())
+		return int64(n), err
+	}
+
+	cw := &countWriter{w: w}
+	bw := writerPool.Get().(*bufio.Writer)
+	bw.Reset(cw)
+	pg.writeSQL(bw)
+	err := bw.Flush()
+	bw.Reset(nil)
+	writerPool.Put(bw)
+	return cw.n, err
+}
@@ -0,0 +1,63 @@
+package pgstring_test
+
+import (
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestWhereSingle(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").Where("a = 1 OR b = 2"),
+		"SELECT * FROM users WHERE a = 1 OR b = 2", nil)
+}
+
+func TestWhereGroupsConditions(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").Where("a = 1 OR b = 2").Where("c = 3"),
+		"SELECT * FROM users WHERE (a = 1 OR b = 2) AND (c = 3)", nil)
+}
+
+func TestWhereKeepsEnclosedConditions(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").Where("(a = 1 OR b = ')')").Where("(a) OR (b)"),
+		"SELECT * FROM users WHERE (a = 1 OR b = ')') AND ((a) OR (b))", nil)
+}
+
+func TestHavingGroupsConditions(t *testing.T) {
+	assertSQL(t, pgstring.Select("user_id").From("orders").GroupBy("user_id").
+		Having("COUNT(*) > 1 OR MAX(total) > 10").HavingCount(">=", 5),
+		"SELECT user_id FROM orders GROUP BY user_id HAVING (COUNT(*) > 1 OR MAX(total) > 10) AND (COUNT(*) >= @having_count)",
+		map[string]any{"having_count": 5})
+}
+
+func TestParseSelectWhere(t *testing.T) {
+	legacy, err := pgstring.ParseSelect("SELECT id FROM users WHERE active OR admin ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSQL(t, legacy.Where("tenant_id = @tenant_id", map[string]any{"tenant_id": 3}),
+		"SELECT id FROM users WHERE (active OR admin) AND (tenant_id = @tenant_id) ORDER BY id",
+		map[string]any{"tenant_id": 3})
+}
+
+func TestSetMerges(t *testing.T) {
+	assertSQL(t, pgstring.Update("users").Set(map[string]any{"name": "a"}).Set(map[string]any{"email": "b"}).
+		Where("id = @id", map[string]any{"id": 1}),
+		"UPDATE users SET name = @name, email = @email WHERE id = @id",
+		map[string]any{"name": "a", "email": "b", "id": 1})
+}
+
+func TestLimitReplaces(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").Limit(10).Offset(20).Limit(5).Offset(0),
+		"SELECT * FROM users LIMIT 5 OFFSET 0", nil)
+}
+
+func TestHavingSumQualified(t *testing.T) {
+	assertSQL(t, pgstring.Select("o.user_id").From("orders o").GroupBy("o.user_id").HavingSum("o.amount", ">", 1000),
+		"SELECT o.user_id FROM orders o GROUP BY o.user_id HAVING SUM(o.amount) > @o_amount_sum",
+		map[string]any{"o_amount_sum": 1000})
+}
+
+func TestBetweenQualified(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("orders o").Between("o.total", 10, 20),
+		"SELECT * FROM orders o WHERE o.total BETWEEN @o_total_start AND @o_total_end",
+		map[string]any{"o_total_start": 10, "o_total_end": 20})
+}
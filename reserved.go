@@ -0,0 +1,47 @@
+package pgstring
+
+import "strings"
+
+// reservedWords are the Postgres key words that can't be used as bare column names,
+// including those reserved apart from function and type names
+var reservedWords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "asymmetric": true, "authorization": true, "binary": true,
+	"both": true, "case": true, "cast": true, "check": true, "collate": true, "collation": true,
+	"column": true, "concurrently": true, "constraint": true, "create": true, "cross": true,
+	"current_catalog": true, "current_date": true, "current_role": true, "current_schema": true,
+	"current_time": true, "current_timestamp": true, "current_user": true, "default": true,
+	"deferrable": true, "desc": true, "distinct": true, "do": true, "else": true, "end": true,
+	"except": true, "false": true, "fetch": true, "for": true, "foreign": true, "freeze": true,
+	"from": true, "full": true, "grant": true, "group": true, "having": true, "ilike": true,
+	"in": true, "initially": true, "inner": true, "intersect": true, "into": true, "is": true,
+	"isnull": true, "join": true, "lateral": true, "leading": true, "left": true, "like": true,
+	"limit": true, "localtime": true, "localtimestamp": true, "natural": true, "not": true,
+	"notnull": true, "null": true, "offset": true, "on": true, "only": true, "or": true,
+	"order": true, "outer": true, "overlaps": true, "placing": true, "primary": true,
+	"references": true, "returning": true, "right": true, "select": true, "session_user": true,
+	"similar": true, "some": true, "symmetric": true, "system_user": true, "table": true,
+	"tablesample": true, "then": true, "to": true, "trailing": true, "true": true, "union": true,
+	"unique": true, "user": true, "using": true, "variadic": true, "verbose": true, "when": true,
+	"where": true, "window": true, "with": true,
+}
+
+// quoteColumn double-quotes a column name that is a reserved word, e.g. order becomes
+// "order". The quoted name is lower-cased, the name Postgres folds the bare word to;
+// anything else, including qualified names and expressions, is returned as is.
+func quoteColumn(name string) string {
+	if lower := strings.ToLower(name); reservedWords[lower] {
+		return quoteIdent(lower)
+	}
+	return name
+}
+
+// writeColumns writes a comma-separated column list, quoting reserved words
+func writeColumns(w sqlWriter, columns []string) {
+	for i, column := range columns {
+		if i > 0 {
+			w.WriteString(", ")
+		}
+		w.WriteString(quoteColumn(column))
+	}
+}
@@ -0,0 +1,181 @@
+package pgstring
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SchemaTable maps a model struct to its table for schema generation
+type SchemaTable struct {
+	Name  string
+	Model any
+	// Options are CreateTable options for the table
+	Options []any
+}
+
+// Schema is a set of models whose DDL is generated together, ordered so every table is
+// created after the tables its references= foreign keys point to
+type Schema struct {
+	tables     []SchemaTable // in dependency order
+	ddl        map[string]tableDDL
+	extensions []string
+	err        error
+}
+
+// NewSchema creates a Schema from tables. References to tables outside the schema are left
+// to the database; self-references are fine, but reference cycles are an error.
+func NewSchema(tables ...SchemaTable) Schema {
+	s := Schema{ddl: make(map[string]tableDDL, len(tables))}
+	for _, table := range tables {
+		if _, ok := s.ddl[table.Name]; ok {
+			return Schema{err: fmt.Errorf("table %s is in the schema twice", table.Name)}
+		}
+		t, err := tableDDLOf(table.Name, table.Model, table.Options)
+		if err != nil {
+			return Schema{err: fmt.Errorf("%s: %w", table.Name, err)}
+		}
+		s.ddl[table.Name] = t
+	}
+
+	// Repeatedly take the first table whose references are all created, keeping the given
+	// order where the foreign keys allow it
+	remaining := slices.Clone(tables)
+	for len(remaining) > 0 {
+		next := slices.IndexFunc(remaining, func(table SchemaTable) bool {
+			return !slices.ContainsFunc(s.ddl[table.Name].references, func(ref string) bool {
+				return ref != table.Name && slices.ContainsFunc(remaining, func(t SchemaTable) bool { return t.Name == ref })
+			})
+		})
+		if next < 0 {
+			names := make([]string, len(remaining))
+			for i, table := range remaining {
+				names[i] = table.Name
+			}
+			return Schema{err: fmt.Errorf("foreign key cycle between %s", strings.Join(names, ", "))}
+		}
+		s.tables = append(s.tables, remaining[next])
+		remaining = slices.Delete(remaining, next, next+1)
+	}
+	return s
+}
+
+// WithExtensions returns the schema with extensions created before any table
+func (s Schema) WithExtensions(names ...string) Schema {
+	s.extensions = append(slices.Clip(s.extensions), names...)
+	return s
+}
+
+// Err returns the error recorded while building the schema
+func (s Schema) Err() error {
+	return s.err
+}
+
+// Tables returns the schema's tables in dependency order: every table comes after the
+// tables it references. Reverse it to truncate or drop tables in a safe order.
+func (s Schema) Tables() []string {
+	names := make([]string, len(s.tables))
+	for i, table := range s.tables {
+		names[i] = table.Name
+	}
+	return names
+}
+
+// DependsOn returns the tables of the schema that table's foreign keys reference, not
+// counting itself
+func (s Schema) DependsOn(table string) []string {
+	var deps []string
+	for _, ref := range s.ddl[table].references {
+		if _, ok := s.ddl[ref]; ok && ref != table {
+			deps = append(deps, ref)
+		}
+	}
+	return deps
+}
+
+// Script returns the schema's DDL: extensions, each enum type once, DROP TABLE for tables
+// with the drop option in reverse dependency order, then CREATE TABLE and CREATE INDEX for
+// each table in dependency order
+func (s Schema) Script() Script {
+	if s.err != nil {
+		return NewScript(PgString{}.fail(s.err))
+	}
+
+	var script Script
+	for _, name := range s.extensions {
+		script = script.Add(ddl("CREATE EXTENSION IF NOT EXISTS "+name, ""))
+	}
+
+	var enums []string
+	for _, table := range s.tables {
+		for _, enum := range s.ddl[table.Name].enums {
+			if sql := enum.String(); !slices.Contains(enums, sql) {
+				enums = append(enums, sql)
+				script = script.Add(enum)
+			}
+		}
+	}
+
+	for i := len(s.tables) - 1; i >= 0; i-- {
+		script = script.Add(s.ddl[s.tables[i].Name].drop...)
+	}
+	for _, table := range s.tables {
+		t := s.ddl[table.Name]
+		script = script.Add(t.create).Add(t.indexes...)
+	}
+	return script
+}
+
+// CloneSchema returns the DDL copying the schema's tables from schema from into a new schema
+// to, for schema-per-tenant deployments: CREATE SCHEMA, then CREATE TABLE ... (LIKE ...
+// INCLUDING ALL) per table, which copies defaults, constraints and indexes. LIKE doesn't
+// copy foreign keys, so those between the schema's tables are added to point at the copies.
+func (s Schema) CloneSchema(from, to string) Script {
+	if s.err != nil {
+		return NewScript(PgString{}.fail(s.err))
+	}
+
+	script := NewScript(ddl("CREATE SCHEMA "+to, ""))
+	for _, table := range s.tables {
+		name := unqualified(table.Name)
+		script = script.Add(CreateTableLike(to+"."+name, from+"."+name, "ALL"))
+	}
+	for _, table := range s.tables {
+		name := unqualified(table.Name)
+		for _, fk := range s.ddl[table.Name].foreignKeys {
+			if _, ok := s.ddl[fk.table]; !ok {
+				continue
+			}
+			script = script.Add(AlterTable(to+"."+name).AddForeignKey(
+				name+"_"+fk.column+"_fkey", []string{fk.column}, to+"."+unqualified(fk.table), fk.columns, fk.deferral))
+		}
+	}
+	return script
+}
+
+// unqualified strips the schema from a table name
+func unqualified(table string) string {
+	if _, name, ok := strings.Cut(table, "."); ok {
+		return name
+	}
+	return table
+}
+
+// CreateSchemaFor returns the DDL creating the tables of objs in foreign key order; see
+// Schema.Script. objs are SchemaTable values, or models that are registered or implement
+// Tabler.
+func CreateSchemaFor(objs ...any) Script {
+	tables := make([]SchemaTable, len(objs))
+	for i, obj := range objs {
+		if table, ok := obj.(SchemaTable); ok {
+			tables[i] = table
+			continue
+		}
+		m, err := modelOf(obj)
+		if err != nil {
+			return NewScript(PgString{}.fail(fmt.Errorf("%T has no table name, use a SchemaTable: %w", obj, err)))
+		}
+		tables[i] = SchemaTable{Name: m.table, Model: obj}
+	}
+	return NewSchema(tables...).Script()
+}
@@ -0,0 +1,79 @@
+package pgstring
+
+import (
+	"io"
+	"slices"
+	"strings"
+)
+
+// scriptSeparator ends every statement but the last in a rendered Script
+const scriptSeparator = ";\n"
+
+// Script is an ordered list of statements. Executing several statements in one string only
+// works over the simple query protocol, so drivers like pgx should run Statements one by one.
+type Script struct {
+	statements []PgString
+	searchPath []string
+}
+
+// NewScript creates a Script from statements
+func NewScript(statements ...PgString) Script {
+	return Script{statements: slices.Clone(statements)}
+}
+
+// Add returns the script with statements appended
+func (s Script) Add(statements ...PgString) Script {
+	s.statements = append(slices.Clip(s.statements), statements...)
+	return s
+}
+
+// Statements returns the script's statements in order
+func (s Script) Statements() []PgString {
+	return slices.Clone(s.statements)
+}
+
+// Len returns the number of statements
+func (s Script) Len() int {
+	return len(s.statements)
+}
+
+// Err returns the first error recorded by any statement
+func (s Script) Err() error {
+	for _, statement := range s.statements {
+		if err := statement.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String renders the statements separated by ";\n"
+func (s Script) String() string {
+	if err := s.Err(); err != nil {
+		return "Error: " + err.Error()
+	}
+
+	var b strings.Builder
+	s.WriteTo(&b)
+	return b.String()
+}
+
+// WriteTo streams the statements to w separated by ";\n", implementing io.WriterTo
+func (s Script) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for i, statement := range s.statements {
+		if i > 0 {
+			n, err := io.WriteString(w, scriptSeparator)
+			total += int64(n)
+			if err != nil {
+				return total, err
+			}
+		}
+		n, err := statement.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,61 @@
+package pgstring
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// SearchPath creates a SET search_path TO statement. Schema names that aren't plain
+// lower-case identifiers, or are reserved words, are double-quoted, so they can't inject
+// SQL, as is "$user".
+func SearchPath(schemas ...string) PgString {
+	return searchPath("SET search_path TO ", schemas)
+}
+
+// SearchPathLocal is like SearchPath but uses SET LOCAL, so the setting ends with the
+// current transaction
+func SearchPathLocal(schemas ...string) PgString {
+	return searchPath("SET LOCAL search_path TO ", schemas)
+}
+
+// searchPath renders a search_path statement after validating the schema names
+func searchPath(head string, schemas []string) PgString {
+	if len(schemas) == 0 {
+		return PgString{}.fail(errors.New("search_path needs at least one schema"))
+	}
+
+	names := make([]string, len(schemas))
+	for i, schema := range schemas {
+		switch {
+		case schema == "" || strings.ContainsRune(schema, 0):
+			return PgString{}.fail(errors.New("invalid schema name " + quoteLiteral(schema)))
+		default:
+			names[i] = quoteName(schema)
+		}
+	}
+	return PgString{}.with(clause{kind: clauseRaw, sql: head + strings.Join(names, ", ")})
+}
+
+// isPlainIdent reports whether name is a lower-case identifier that needs no quoting
+func isPlainIdent(name string) bool {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c >= 'A' && c <= 'Z' || !isIdentChar(c) && c != '$' || i == 0 && !isIdentStart(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithSearchPath returns the script with a search_path that pgexec.ExecScript sets for the
+// script's statements only
+func (s Script) WithSearchPath(schemas ...string) Script {
+	s.searchPath = slices.Clone(schemas)
+	return s
+}
+
+// SearchPath returns the schemas set with WithSearchPath
+func (s Script) SearchPath() []string {
+	return slices.Clone(s.searchPath)
+}
@@ -0,0 +1,147 @@
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/oliverpaddock/pgstring"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the encoding of a fixtures document
+type Format string
+
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// LoadFixtures parses a document mapping table names to lists of rows and returns one
+// INSERT per row, built with pgstring's Map insert. Tables keep the order they appear in
+// the document, so parents can be listed before the tables referencing them.
+//
+//	users:
+//	  - id: 1
+//	    name: alice
+//	orders:
+//	  - id: 10
+//	    user_id: 1
+func LoadFixtures(r io.Reader, format Format) ([]pgstring.PgString, error) {
+	var tables []fixtureTable
+	var err error
+
+	switch format {
+	case JSON:
+		tables, err = decodeJSONFixtures(r)
+	case YAML:
+		tables, err = decodeYAMLFixtures(r)
+	default:
+		return nil, fmt.Errorf("unsupported fixture format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []pgstring.PgString
+	for _, table := range tables {
+		for _, row := range table.rows {
+			statements = append(statements, pgstring.InsertInto(table.name).Map(row))
+		}
+	}
+	return statements, nil
+}
+
+// fixtureTable holds the rows of one table in a fixtures document
+type fixtureTable struct {
+	name string
+	rows []map[string]any
+}
+
+// decodeJSONFixtures reads the top-level object token by token to keep the table order
+func decodeJSONFixtures(r io.Reader) ([]fixtureTable, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("fixtures must be an object of table names to rows")
+	}
+
+	var tables []fixtureTable
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := tok.(string)
+
+		var rows []map[string]any
+		if err := dec.Decode(&rows); err != nil {
+			return nil, fmt.Errorf("table %s: %w", name, err)
+		}
+		for _, row := range rows {
+			for column, value := range row {
+				row[column] = jsonValue(value)
+			}
+		}
+		tables = append(tables, fixtureTable{name: name, rows: rows})
+	}
+
+	_, err := dec.Token()
+	return tables, err
+}
+
+// jsonValue converts json.Number values into int64 or float64 so they bind as numbers
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = jsonValue(v[k])
+		}
+	}
+	return value
+}
+
+// decodeYAMLFixtures walks the top-level mapping node to keep the table order
+func decodeYAMLFixtures(r io.Reader) ([]fixtureTable, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("fixtures must be a mapping of table names to rows")
+	}
+
+	var tables []fixtureTable
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		name := root.Content[i].Value
+
+		var rows []map[string]any
+		if err := root.Content[i+1].Decode(&rows); err != nil {
+			return nil, fmt.Errorf("table %s: %w", name, err)
+		}
+		tables = append(tables, fixtureTable{name: name, rows: rows})
+	}
+	return tables, nil
+}
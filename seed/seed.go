@@ -0,0 +1,97 @@
+// Package seed turns slices of structs into INSERT statements grouped per table,
+// for test fixtures and demo environments.
+package seed
+
+import (
+	"reflect"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+// Seeder collects rows per table and generates bulk INSERT statements for them
+type Seeder struct {
+	order     []string
+	rows      map[string][]any
+	doNothing bool
+}
+
+// Table holds the INSERT statements generated for one table
+type Table struct {
+	Name       string
+	Statements []pgstring.PgString
+}
+
+// New creates an empty Seeder
+func New() *Seeder {
+	return &Seeder{rows: map[string][]any{}}
+}
+
+// Add queues a slice of structs (or struct pointers) to be inserted into table.
+// Tables are generated in the order they were first added. An empty slice generates a
+// statement that fails to build with "no rows", like ValuesBulk.
+func (s *Seeder) Add(table string, rows any) *Seeder {
+	if _, ok := s.rows[table]; !ok {
+		s.order = append(s.order, table)
+	}
+	s.rows[table] = append(s.rows[table], rows)
+	return s
+}
+
+// OnConflictDoNothing makes every generated INSERT skip rows that already exist,
+// so seed scripts can be re-run against a populated database
+func (s *Seeder) OnConflictDoNothing() *Seeder {
+	s.doNothing = true
+	return s
+}
+
+// Tables generates the INSERT statements for each table. Each slice passed to Add becomes one
+// multi-row INSERT, split into chunks when it exceeds pgstring.MaxParams parameters.
+func (s *Seeder) Tables() []Table {
+	tables := make([]Table, 0, len(s.order))
+	for _, name := range s.order {
+		table := Table{Name: name}
+		for _, rows := range s.rows[name] {
+			table.Statements = append(table.Statements, s.insert(name, rows).Chunks()...)
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// Statements returns the statements of every table in order
+func (s *Seeder) Statements() []pgstring.PgString {
+	var statements []pgstring.PgString
+	for _, table := range s.Tables() {
+		statements = append(statements, table.Statements...)
+	}
+	return statements
+}
+
+// insert builds the bulk INSERT for one slice of rows
+func (s *Seeder) insert(table string, rows any) pgstring.PgString {
+	pg := pgstring.InsertInto(table).Obj(elemZero(rows)).ValuesBulk(rows)
+	if s.doNothing {
+		pg = pg.OnConflict("").DoNothing()
+	}
+	return pg
+}
+
+// elemZero returns a zero value of the element type of a slice, or rows itself if it isn't one
+func elemZero(rows any) any {
+	typ := reflect.TypeOf(rows)
+	if typ == nil {
+		return nil
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+		return rows
+	}
+
+	elem := typ.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return reflect.New(elem).Interface()
+}
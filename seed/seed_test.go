@@ -0,0 +1,38 @@
+package seed_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oliverpaddock/pgstring/seed"
+)
+
+type user struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestStatements(t *testing.T) {
+	statements := seed.New().Add("users", []user{{1, "a"}, {2, "b"}}).OnConflictDoNothing().Statements()
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements, want 1", len(statements))
+	}
+	sql, _, err := statements[0].Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT INTO users (id, name) VALUES (@id_0, @name_0), (@id_1, @name_1) ON CONFLICT DO NOTHING"
+	if sql != want {
+		t.Errorf("got  %s\nwant %s", sql, want)
+	}
+}
+
+func TestEmptyRows(t *testing.T) {
+	statements := seed.New().Add("users", []user{}).Statements()
+	if len(statements) != 1 {
+		t.Fatalf("got %d statements, want 1", len(statements))
+	}
+	if _, _, err := statements[0].Build(); err == nil || !strings.Contains(err.Error(), "no rows") {
+		t.Errorf("Build error = %v, want no rows", err)
+	}
+}
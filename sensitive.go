@@ -0,0 +1,79 @@
+package pgstring
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Redacted is the value RedactedArgs shows for sensitive args
+const Redacted = "[redacted]"
+
+// redactedLiteral renders as '[redacted]' in DebugString
+type redactedLiteral struct{}
+
+func (redactedLiteral) String() string { return Redacted }
+
+// Sensitive marks the named args as sensitive, like fields tagged db:",sensitive", so
+// DebugString, slow query reports and RedactedArgs don't show their values, e.g. for a
+// token bound in a Where map:
+//
+//	Where("token = @token", map[string]any{"token": token}).Sensitive("token")
+func (pg PgString) Sensitive(names ...string) PgString {
+	pg.sensitive = append(slices.Clip(pg.sensitive), names...)
+	return pg
+}
+
+// RedactedArgs returns the named args with the values of sensitive args replaced by
+// Redacted, for logging alongside the SQL text
+func (pg PgString) RedactedArgs() map[string]any {
+	return pg.redact(pg.namedArgs(), Redacted)
+}
+
+// redact returns args with the sensitive args replaced by placeholder
+func (pg PgString) redact(args map[string]any, placeholder any) map[string]any {
+	sensitive := pg.sensitiveArgs()
+	if len(sensitive) == 0 {
+		return args
+	}
+	redacted := make(map[string]any, len(args))
+	for name, value := range args {
+		if sensitive[name] {
+			value = placeholder
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// sensitiveArgs returns the names of the sensitive args: tagged fields, including the
+// per-row args of ValuesBulk, and the names given to Sensitive
+func (pg PgString) sensitiveArgs() map[string]bool {
+	names := make(map[string]bool)
+	for _, arg := range pg.args {
+		if arg.sensitive {
+			names[arg.name] = true
+		}
+	}
+	for _, c := range pg.clauses {
+		for i := range c.rows {
+			for _, column := range c.sensitive {
+				names[bulkArgName(column, i)] = true
+			}
+		}
+	}
+	for _, name := range pg.sensitive {
+		names[name] = true
+	}
+	return names
+}
+
+// sensitiveColumns returns the columns of a struct type's sensitive fields
+func sensitiveColumns(typ reflect.Type) []string {
+	var columns []string
+	for _, field := range structInfoOf(typ).fields {
+		if field.sensitive {
+			columns = append(columns, field.name)
+		}
+	}
+	return columns
+}
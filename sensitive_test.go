@@ -0,0 +1,64 @@
+package pgstring_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+type credential struct {
+	ID    int    `db:"id,primarykey"`
+	Email string `db:"email,sensitive"`
+	Token string `db:"token,sensitive"`
+	Note  string `db:"note"`
+}
+
+func TestSensitiveFields(t *testing.T) {
+	q := pgstring.InsertInto("credentials").Obj(credential{}).Values(credential{ID: 1, Email: "a@example.com", Token: "t0k", Note: "n"})
+	want := "INSERT INTO credentials (id, email, token, note) VALUES (1, '[redacted]', '[redacted]', 'n')"
+	if got := q.DebugString(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if got := q.InlineArgs().DebugString(); got != want {
+		t.Errorf("inlined: got  %s\nwant %s", got, want)
+	}
+	wantArgs := map[string]any{"id": 1, "email": pgstring.Redacted, "token": pgstring.Redacted, "note": "n"}
+	if got := q.RedactedArgs(); !reflect.DeepEqual(got, wantArgs) {
+		t.Errorf("got %v, want %v", got, wantArgs)
+	}
+	// Build still binds the real values
+	if _, args, _ := q.Build(); args["token"] != "t0k" {
+		t.Errorf("Build args redacted: %v", args)
+	}
+}
+
+func TestSensitiveBulkRows(t *testing.T) {
+	rows := []credential{{ID: 1, Token: "a"}, {ID: 2, Token: "b"}}
+	got := pgstring.InsertInto("credentials").Obj(credential{}).ValuesBulk(rows).RedactedArgs()
+	for _, name := range []string{"token_0", "token_1", "email_0", "email_1"} {
+		if got[name] != pgstring.Redacted {
+			t.Errorf("%s = %v, want redacted", name, got[name])
+		}
+	}
+	if got["id_1"] != 2 {
+		t.Errorf("id_1 = %v", got["id_1"])
+	}
+}
+
+func TestSensitiveNames(t *testing.T) {
+	q := pgstring.Select("*").From("sessions").Where("token = @token AND user_id = @user_id",
+		map[string]any{"token": "secret", "user_id": 3}).Sensitive("token")
+	if got, want := q.DebugString(), "SELECT * FROM sessions WHERE token = '[redacted]' AND user_id = 3"; got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestFingerprintIgnoresInlinedArgs(t *testing.T) {
+	q := func(token string) pgstring.PgString {
+		return pgstring.Select("*").From("sessions").Where("token = @token", map[string]any{"token": token}).InlineArgs()
+	}
+	if q("a").Fingerprint() != q("b").Fingerprint() {
+		t.Error("fingerprint depends on inlined args")
+	}
+}
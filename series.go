@@ -0,0 +1,61 @@
+package pgstring
+
+import (
+	"reflect"
+	"time"
+)
+
+// GenerateSeries returns generate_series(start, end, step) for use as a FROM source with
+// FromExpr. Plain values are bound as @series_start, @series_end and @series_step and cast
+// so Postgres can pick the overload: integers to bigint, floats to numeric, time.Time and
+// strings to timestamptz, and a time.Duration or string step to interval. Exprs are used as is.
+//
+// Gap-filled daily counts, with a row for days without orders:
+//
+//	SelectExpr("s.day", Coalesce("count(o.id)", 0).As("orders")).
+//		FromExpr(GenerateSeries(from, to, 24*time.Hour), "s(day)").
+//		LeftJoin("orders o", "date_trunc('day', o.created_at) = s.day").
+//		GroupBy("s.day").OrderBy("s.day")
+func GenerateSeries(start, end, step any) Expr {
+	return call("generate_series", []any{
+		seriesArg("series_start", start, false),
+		seriesArg("series_end", end, false),
+		seriesArg("series_step", step, true),
+	})
+}
+
+// seriesArg binds a generate_series argument with the cast for its Go type
+func seriesArg(name string, value any, step bool) any {
+	if e, ok := value.(Expr); ok {
+		return e
+	}
+
+	arg := Arg(name, value)
+	switch v := value.(type) {
+	case time.Duration:
+		arg.sql += "::interval"
+	case time.Time:
+		arg.sql += "::timestamptz"
+	case string:
+		if step {
+			arg.sql += "::interval"
+		} else {
+			arg.sql += "::timestamptz"
+		}
+	default:
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			arg.sql += "::bigint"
+		case reflect.Float32, reflect.Float64:
+			arg.sql += "::numeric"
+		}
+	}
+	return arg
+}
+
+// FromExpr adds a FROM clause selecting from a set-returning expression such as
+// GenerateSeries. alias may list column names, e.g. "s(day)".
+func (pg PgString) FromExpr(e Expr, alias string) PgString {
+	return pg.with(clause{kind: clauseFrom, sql: e.sql + " AS " + alias}).withExpr(e)
+}
@@ -0,0 +1,44 @@
+package pgstring
+
+// Table is a table name, optionally schema-qualified, that renders quoted where needed so
+// reserved words and mixed-case names work wherever builders take a table
+type Table struct {
+	Schema string
+	Name   string
+}
+
+// T creates a Table from a schema and a name; an empty schema leaves the name unqualified.
+// Names are taken as stored, so T("", "Users") refers to "Users", not users.
+//
+//	pgstring.InsertInto(pgstring.T("public", "user").String())
+func T(schema, name string) Table {
+	return Table{Schema: schema, Name: name}
+}
+
+// String returns the quoted, qualified name, e.g. public."user"
+func (t Table) String() string {
+	if t.Schema == "" {
+		return quoteName(t.Name)
+	}
+	return quoteName(t.Schema) + "." + quoteName(t.Name)
+}
+
+// As returns the table with an alias, for From and Join: public."order" AS o
+func (t Table) As(alias string) string {
+	return t.String() + " AS " + quoteName(alias)
+}
+
+// Column returns a column qualified by the table, e.g. "user".id, for join conditions
+// on unaliased tables
+func (t Table) Column(column string) string {
+	return t.String() + "." + quoteName(column)
+}
+
+// quoteName returns name as is when it is a plain lower-case identifier that isn't a
+// reserved word, otherwise double-quoted
+func quoteName(name string) string {
+	if isPlainIdent(name) && !reservedWords[name] {
+		return name
+	}
+	return quoteIdent(name)
+}
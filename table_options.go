@@ -0,0 +1,182 @@
+package pgstring
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// TableOptions holds table-level settings for CreateTable that can't be expressed with struct tags
+type TableOptions struct {
+	IfNotExists bool // CREATE TABLE IF NOT EXISTS
+	Drop        bool // DROP TABLE IF EXISTS before creating
+	Cascade     bool // drop dependent objects too, requires Drop
+	Temporary   bool // CREATE TEMPORARY TABLE
+	Unlogged    bool // CREATE UNLOGGED TABLE
+	Tablespace  string
+	PartitionBy string   // partitioning clause, e.g. "RANGE (created_at)"
+	Inherits    []string // parent tables
+
+	// Storage sets storage parameters, e.g. {"fillfactor": 70, "autovacuum_enabled": false}
+	Storage map[string]any
+
+	// Exclude adds EXCLUDE constraints, e.g. to prevent overlapping bookings
+	Exclude []ExcludeConstraint
+}
+
+// merge combines o into t. Flags and lists accumulate; a setting given twice must agree.
+func (t *TableOptions) merge(o TableOptions) error {
+	t.IfNotExists = t.IfNotExists || o.IfNotExists
+	t.Drop = t.Drop || o.Drop
+	t.Cascade = t.Cascade || o.Cascade
+	t.Temporary = t.Temporary || o.Temporary
+	t.Unlogged = t.Unlogged || o.Unlogged
+	t.Inherits = append(t.Inherits, o.Inherits...)
+	t.Exclude = append(t.Exclude, o.Exclude...)
+
+	if o.Tablespace != "" {
+		if t.Tablespace != "" && t.Tablespace != o.Tablespace {
+			return fmt.Errorf("conflicting tablespaces %s and %s", t.Tablespace, o.Tablespace)
+		}
+		t.Tablespace = o.Tablespace
+	}
+	for name, value := range o.Storage {
+		if existing, ok := t.Storage[name]; ok && fmt.Sprint(existing) != fmt.Sprint(value) {
+			return fmt.Errorf("conflicting values for storage parameter %s", name)
+		}
+		if t.Storage == nil {
+			t.Storage = make(map[string]any, len(o.Storage))
+		}
+		t.Storage[name] = value
+	}
+	if o.PartitionBy != "" {
+		if t.PartitionBy != "" && t.PartitionBy != o.PartitionBy {
+			return fmt.Errorf("conflicting partitioning %s and %s", t.PartitionBy, o.PartitionBy)
+		}
+		t.PartitionBy = o.PartitionBy
+	}
+	return nil
+}
+
+// validate reports combinations Postgres would reject
+func (t TableOptions) validate() error {
+	if t.Temporary && t.Unlogged {
+		return fmt.Errorf("a table can't be both temporary and unlogged")
+	}
+	if t.Cascade && !t.Drop {
+		return fmt.Errorf("cascade requires the drop option")
+	}
+	if t.PartitionBy != "" && len(t.Inherits) > 0 {
+		return fmt.Errorf("a partitioned table can't inherit from other tables")
+	}
+	for _, exclude := range t.Exclude {
+		if len(exclude.Elements) == 0 {
+			return fmt.Errorf("exclude constraint %q has no elements", exclude.Name)
+		}
+	}
+	return nil
+}
+
+// ExcludeConstraint is a table-level EXCLUDE constraint:
+//
+//	CONSTRAINT name EXCLUDE USING gist (room_id WITH =, during WITH &&) WHERE (predicate)
+type ExcludeConstraint struct {
+	Name     string // optional constraint name
+	Using    string // index method, gist when empty
+	Elements []ExcludeElement
+	Where    string // optional predicate limiting the constraint to some rows
+}
+
+// ExcludeElement is a column or expression compared with Operator by an EXCLUDE constraint
+type ExcludeElement struct {
+	Column   string
+	Operator string
+}
+
+// String renders the constraint definition
+func (e ExcludeConstraint) String() string {
+	var b strings.Builder
+	if e.Name != "" {
+		b.WriteString("CONSTRAINT ")
+		b.WriteString(e.Name)
+		b.WriteByte(' ')
+	}
+
+	using := e.Using
+	if using == "" {
+		using = "gist"
+	}
+	b.WriteString("EXCLUDE USING ")
+	b.WriteString(using)
+	b.WriteString(" (")
+	for i, element := range e.Elements {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(element.Column)
+		b.WriteString(" WITH ")
+		b.WriteString(element.Operator)
+	}
+	b.WriteByte(')')
+
+	if e.Where != "" {
+		b.WriteString(" WHERE (")
+		b.WriteString(e.Where)
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// storageClause renders the storage parameters as a WITH clause, sorted by name
+func (t TableOptions) storageClause() string {
+	if len(t.Storage) == 0 {
+		return ""
+	}
+
+	names := slices.Sorted(maps.Keys(t.Storage))
+	params := make([]string, len(names))
+	for i, name := range names {
+		params[i] = fmt.Sprintf("%s = %v", name, t.Storage[name])
+	}
+	return "WITH (" + strings.Join(params, ", ") + ")"
+}
+
+// tableOptionsOf merges CreateTable's options, TableOption* strings and TableOptions values,
+// and validates the result
+func tableOptionsOf(options []any) (TableOptions, error) {
+	var merged TableOptions
+	for _, option := range options {
+		var o TableOptions
+		switch v := option.(type) {
+		case string:
+			switch v {
+			case TableOptionIfNotExists:
+				o.IfNotExists = true
+			case TableOptionDrop:
+				o.Drop = true
+			case TableOptionDropCascade:
+				o.Drop, o.Cascade = true, true
+			default:
+				return TableOptions{}, fmt.Errorf("unknown table option %q", v)
+			}
+		case TableOptions:
+			o = v
+		case *TableOptions:
+			if v != nil {
+				o = *v
+			}
+		default:
+			return TableOptions{}, fmt.Errorf("unsupported table option %T", option)
+		}
+
+		if err := merged.merge(o); err != nil {
+			return TableOptions{}, err
+		}
+	}
+
+	if err := merged.validate(); err != nil {
+		return TableOptions{}, err
+	}
+	return merged, nil
+}
@@ -0,0 +1,140 @@
+package pgstring
+
+import (
+	"slices"
+	"strings"
+)
+
+// Tables returns the tables the statement reads and writes, in the order they appear, so
+// caching layers and routers can decide from the builder alone. Tables are recorded as the
+// builders name them, with aliases stripped; derived tables, CTEs and set-returning
+// functions in FROM aren't tables and are left out. DDL builders (CreateTable, AlterTable,
+// CreateTableAs, ...) write the table they define.
+func (pg PgString) Tables() (read, written []string) {
+	// The first FROM of a DELETE names the table being written
+	deleting := len(pg.clauses) > 0 && pg.clauses[0].kind == clauseDelete
+	for _, c := range pg.clauses {
+		if c.kind == clauseFrom && deleting {
+			c.writes = true
+			deleting = false
+		}
+		if c.table == "" {
+			continue
+		}
+		if c.writes {
+			written = appendUnique(written, c.table)
+		} else {
+			read = appendUnique(read, c.table)
+		}
+	}
+	return read, written
+}
+
+// appendUnique appends table unless it is already listed
+func appendUnique(tables []string, table string) []string {
+	if slices.Contains(tables, table) {
+		return tables
+	}
+	return append(tables, table)
+}
+
+// tableName returns the table named at the start of a FROM or JOIN item, without its alias.
+// Derived tables and function calls return "".
+func tableName(item string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(item), " ")
+	if name == "" || strings.ContainsAny(name, "()@") {
+		return ""
+	}
+	return name
+}
+
+// ddl creates a raw statement that writes table
+func ddl(sql, table string) PgString {
+	return PgString{}.with(clause{kind: clauseRaw, sql: sql, table: table, writes: true})
+}
+
+// IsReadOnly reports whether the statement only reads, so it can be routed to a replica.
+// Raw SQL is assumed to write, since its statement kind isn't known.
+func (pg PgString) IsReadOnly() bool {
+	if pg.err != nil || len(pg.clauses) == 0 {
+		return false
+	}
+	if first := pg.clauses[0].kind; first != clauseSelect && first != clauseSelectDistinct && first != clauseRaw {
+		return false
+	}
+	for _, c := range pg.clauses {
+		if c.writes || (c.kind == clauseRaw && c.table == "") {
+			return false
+		}
+	}
+	return true
+}
+
+// Operation returns the SQL keyword of the statement, such as SELECT, INSERT or ALTER TABLE.
+// Raw statements report their first word upper-cased.
+func (pg PgString) Operation() string {
+	if len(pg.clauses) == 0 {
+		return ""
+	}
+	switch c := pg.clauses[0]; c.kind {
+	case clauseRaw:
+		words := strings.Fields(c.sql)
+		if len(words) == 0 {
+			return ""
+		}
+		return strings.ToUpper(words[0])
+	case clauseSelectDistinct:
+		return "SELECT"
+	case clauseInsert:
+		return "INSERT"
+	default:
+		return clauseKeywords[c.kind]
+	}
+}
+
+// StatementKind is the kind of statement a builder produces
+type StatementKind string
+
+const (
+	KindSelect StatementKind = "SELECT"
+	KindInsert StatementKind = "INSERT"
+	KindUpdate StatementKind = "UPDATE"
+	KindDelete StatementKind = "DELETE"
+	KindDDL    StatementKind = "DDL"
+	KindOther  StatementKind = "OTHER"
+)
+
+// Kind returns the kind of statement from the clauses it was built from, for logging,
+// metrics and routing. SELECT ... INTO and the schema helpers are DDL; raw SQL is OTHER.
+func (pg PgString) Kind() StatementKind {
+	if pg.err != nil || len(pg.clauses) == 0 {
+		return KindOther
+	}
+	switch c := pg.clauses[0]; c.kind {
+	case clauseSelect, clauseSelectDistinct:
+		for _, c := range pg.clauses {
+			if c.kind == clauseInto {
+				return KindDDL
+			}
+		}
+		return KindSelect
+	case clauseInsert:
+		return KindInsert
+	case clauseUpdate:
+		return KindUpdate
+	case clauseDelete:
+		return KindDelete
+	case clauseAlterTable:
+		return KindDDL
+	case clauseRaw:
+		// Raw clauses the package creates record what they touch: ddl() statements write
+		// their table, and generated queries like TreeQuery read theirs
+		if c.writes {
+			return KindDDL
+		}
+		if c.table != "" {
+			return KindSelect
+		}
+	}
+	return KindOther
+}
@@ -0,0 +1,80 @@
+package pgstring
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Template is a query rendered once, typically at init, and bound to new args per request
+// without re-running reflection or string assembly for the query shape
+type Template struct {
+	sql        string
+	params     []string
+	defaults   map[string]any
+	nullPolicy NullPolicy
+}
+
+// Prepare renders the query into a Template. Args already bound to the query become the
+// template's defaults.
+func (pg PgString) Prepare() (*Template, error) {
+	sql, args, err := pg.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Template{sql: sql, params: placeholders(sql), defaults: args, nullPolicy: pg.nullPolicy}, nil
+}
+
+// MustPrepare is like Prepare but panics if the query has an error. It simplifies
+// declaring templates as package-level variables.
+func MustPrepare(pg PgString) *Template {
+	t, err := pg.Prepare()
+	if err != nil {
+		panic("pgstring: " + err.Error())
+	}
+	return t
+}
+
+// String returns the template's SQL
+func (t *Template) String() string {
+	return t.sql
+}
+
+// Params returns the named placeholders referenced by the template, in order of appearance
+func (t *Template) Params() []string {
+	return t.params
+}
+
+// Bind returns the template's SQL and named args. Each arg is a map[string]any or a struct
+// whose fields are applied over the defaults in order; every placeholder must end up bound.
+func (t *Template) Bind(args ...any) (string, map[string]any, error) {
+	result := make(map[string]any, len(t.defaults)+len(t.params))
+	maps.Copy(result, t.defaults)
+
+	for _, arg := range args {
+		if obj, ok := arg.(map[string]any); ok {
+			maps.Copy(result, obj)
+			continue
+		}
+
+		val, ok := structValue(arg)
+		if !ok {
+			return "", nil, fmt.Errorf("cannot bind %T: args must be a map or a struct", arg)
+		}
+		for _, field := range structInfoOf(val.Type()).fields {
+			value, err := field.bindValue(val, t.nullPolicy)
+			if err != nil {
+				return "", nil, err
+			}
+			result[field.name] = value
+		}
+	}
+
+	for _, param := range t.params {
+		if _, ok := result[param]; !ok {
+			return "", nil, fmt.Errorf("missing named arg %q", param)
+		}
+	}
+
+	return t.sql, result, nil
+}
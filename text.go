@@ -0,0 +1,154 @@
+package pgstring
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lower converts item to lower case, lower(item)
+func Lower(item any) Expr {
+	return call("lower", []any{item})
+}
+
+// Upper converts item to upper case, upper(item)
+func Upper(item any) Expr {
+	return call("upper", []any{item})
+}
+
+// Concat concatenates items, treating NULLs as empty strings, concat(a, b, ...)
+func Concat(items ...any) Expr {
+	return call("concat", items)
+}
+
+// Substr extracts length characters of item starting at from (1-based). A length of
+// zero or less extracts the rest of the string.
+func Substr(item any, from, length int) Expr {
+	args := []any{item, strconv.Itoa(from)}
+	if length > 0 {
+		args = append(args, strconv.Itoa(length))
+	}
+	return call("substr", args)
+}
+
+// TrigramSimilar adds a pg_trgm fuzzy match condition on column. With a threshold above
+// zero rows must have similarity(column, value) >= threshold; otherwise the % operator is
+// used, which compares against pg_trgm.similarity_threshold and can use a trigram index.
+func (pg PgString) TrigramSimilar(column, value string, threshold float64) PgString {
+	valueArg := argName(column) + "_similar"
+	if threshold <= 0 {
+		condition := column + " % @" + valueArg
+		return pg.with(clause{kind: clauseWhere, sql: condition}).withArg(valueArg, value)
+	}
+
+	thresholdArg := argName(column) + "_threshold"
+	condition := "similarity(" + column + ", @" + valueArg + ") >= @" + thresholdArg
+	return pg.with(clause{kind: clauseWhere, sql: condition}).withArg(valueArg, value).withArg(thresholdArg, threshold)
+}
+
+// WhereSearch adds a condition matching term anywhere in any of columns, case-insensitively:
+// (name ILIKE @q OR email ILIKE @q). % and _ in term match themselves. An empty term adds
+// no condition.
+func (pg PgString) WhereSearch(term string, columns ...string) PgString {
+	if term == "" {
+		return pg
+	}
+	if len(columns) == 0 {
+		return pg.fail(errors.New("WhereSearch needs at least one column"))
+	}
+
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		auditIdent("WhereSearch", column)
+		conditions[i] = column + " ILIKE @q" + likeEscape
+	}
+	condition := strings.Join(conditions, " OR ")
+	if len(conditions) > 1 {
+		condition = "(" + condition + ")"
+	}
+	return pg.with(clause{kind: clauseWhere, sql: condition}).withArg("q", "%"+EscapeLike(term)+"%")
+}
+
+// WhereEqCI adds a case-insensitive equality condition, lower(column) = lower(@column_eq),
+// which can use the unique index a unique_ci tag creates. The arg is numbered when the query
+// already binds the name, like WhereEq's.
+func (pg PgString) WhereEqCI(column, value string) PgString {
+	auditIdent("WhereEqCI", column)
+	name := argName(column) + "_eq"
+	for n := 2; pg.hasArg(name); n++ {
+		name = argName(column) + "_eq" + strconv.Itoa(n)
+	}
+	condition := "lower(" + column + ") = lower(@" + name + ")"
+	return pg.with(clause{kind: clauseWhere, sql: condition}).withArg(name, value)
+}
+
+// LikeMatch is how Like and ILike use their pattern
+type LikeMatch int
+
+const (
+	// LikeRaw uses the pattern as written, wildcards included
+	LikeRaw LikeMatch = iota
+	// LikeExact matches the escaped term exactly
+	LikeExact
+	// LikePrefix matches values starting with the escaped term
+	LikePrefix
+	// LikeSuffix matches values ending with the escaped term
+	LikeSuffix
+	// LikeContains matches values containing the escaped term
+	LikeContains
+)
+
+// likeEscape declares the escape character EscapeLike uses
+const likeEscape = ` ESCAPE '\'`
+
+// likeEscaper escapes the LIKE wildcards and the escape character \
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// EscapeLike escapes %, _ and \ in s so it matches itself in a LIKE pattern using
+// ESCAPE '\', e.g. for building a pattern around user input: "%" + EscapeLike(term) + "%"
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Like adds a column LIKE @column_pattern condition, numbering the arg when the query
+// already binds the name. With a LikeMatch other than LikeRaw
+// the pattern is user input: it is escaped, wrapped in wildcards as match says, and
+// compared with ESCAPE '\', so % and _ in it match themselves.
+func (pg PgString) Like(column, pattern string, match ...LikeMatch) PgString {
+	return pg.like("LIKE", column, pattern, match)
+}
+
+// ILike is the case-insensitive Like, column ILIKE @column_pattern
+func (pg PgString) ILike(column, pattern string, match ...LikeMatch) PgString {
+	return pg.like("ILIKE", column, pattern, match)
+}
+
+// like renders a LIKE or ILIKE condition
+func (pg PgString) like(operator, column, pattern string, match []LikeMatch) PgString {
+	name := argName(column) + "_pattern"
+	for n := 2; pg.hasArg(name); n++ {
+		name = argName(column) + "_pattern" + strconv.Itoa(n)
+	}
+	condition := column + " " + operator + " @" + name
+	if len(match) > 1 {
+		return pg.fail(fmt.Errorf("%s %s: at most one LikeMatch", operator, column))
+	}
+	if len(match) == 1 && match[0] != LikeRaw {
+		escaped := EscapeLike(pattern)
+		switch match[0] {
+		case LikeExact:
+			pattern = escaped
+		case LikePrefix:
+			pattern = escaped + "%"
+		case LikeSuffix:
+			pattern = "%" + escaped
+		case LikeContains:
+			pattern = "%" + escaped + "%"
+		default:
+			return pg.fail(fmt.Errorf("%s %s: unknown LikeMatch %d", operator, column, match[0]))
+		}
+		condition += likeEscape
+	}
+	return pg.with(clause{kind: clauseWhere, sql: condition}).withArg(name, pattern)
+}
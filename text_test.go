@@ -0,0 +1,31 @@
+package pgstring_test
+
+import (
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestTrigramSimilar(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").TrigramSimilar("name", "jon", 0),
+		"SELECT * FROM users WHERE name % @name_similar",
+		map[string]any{"name_similar": "jon"})
+}
+
+func TestTrigramSimilarQualified(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users u").TrigramSimilar("u.name", "jon", 0.4),
+		"SELECT * FROM users u WHERE similarity(u.name, @u_name_similar) >= @u_name_threshold",
+		map[string]any{"u_name_similar": "jon", "u_name_threshold": 0.4})
+}
+
+func TestLikeTwice(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users u").Like("u.name", "a", pgstring.LikePrefix).ILike("u.name", "b%"),
+		`SELECT * FROM users u WHERE (u.name LIKE @u_name_pattern ESCAPE '\') AND (u.name ILIKE @u_name_pattern2)`,
+		map[string]any{"u_name_pattern": "a%", "u_name_pattern2": "b%"})
+}
+
+func TestWhereEqCI(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users u").WhereEqCI("u.email", "A@example.com").WhereEqCI("u.email", "b@example.com"),
+		"SELECT * FROM users u WHERE (lower(u.email) = lower(@u_email_eq)) AND (lower(u.email) = lower(@u_email_eq2))",
+		map[string]any{"u_email_eq": "A@example.com", "u_email_eq2": "b@example.com"})
+}
@@ -0,0 +1,74 @@
+package pgstring
+
+import "slices"
+
+// without returns pg with every clause of the given kinds removed. Named args are kept;
+// pgx ignores named args a query doesn't reference.
+func (pg PgString) without(kinds ...clauseKind) PgString {
+	pg.clauses = slices.DeleteFunc(slices.Clone(pg.clauses), func(c clause) bool {
+		return slices.Contains(kinds, c.kind)
+	})
+	return pg
+}
+
+// replace swaps the clauses of c's kind for c, keeping the position of the first one.
+// Without an existing clause c goes before the first clause of a kind in before, or last.
+func (pg PgString) replace(c clause, before ...clauseKind) PgString {
+	pos := slices.IndexFunc(pg.clauses, func(existing clause) bool { return existing.kind == c.kind })
+	if pos < 0 {
+		pos = slices.IndexFunc(pg.clauses, func(existing clause) bool { return slices.Contains(before, existing.kind) })
+	}
+	if pos < 0 {
+		return pg.with(c)
+	}
+
+	clauses := pg.without(c.kind).clauses
+	pos = min(pos, len(clauses))
+	pg.clauses = slices.Insert(clauses, pos, c)
+	return pg
+}
+
+// RemoveLimit removes the LIMIT clause, e.g. to export every row of a paginated query
+func (pg PgString) RemoveLimit() PgString {
+	return pg.without(clauseLimit)
+}
+
+// RemoveOffset removes the OFFSET clause
+func (pg PgString) RemoveOffset() PgString {
+	return pg.without(clauseOffset)
+}
+
+// ClearWhere removes all WHERE conditions
+func (pg PgString) ClearWhere() PgString {
+	return pg.without(clauseWhere)
+}
+
+// ClearOrderBy removes the ORDER BY clause, e.g. when turning a query into its count variant
+func (pg PgString) ClearOrderBy() PgString {
+	return pg.without(clauseOrderBy)
+}
+
+// ReplaceOrderBy replaces the ORDER BY clause with order
+func (pg PgString) ReplaceOrderBy(order string) PgString {
+	return pg.replace(clause{kind: clauseOrderBy, sql: order}, clauseLimit, clauseOffset)
+}
+
+// ReplaceSelect replaces the selected fields, accepting the same values as Select:
+//
+//	base.ReplaceSelect("COUNT(*)").ClearOrderBy().RemoveLimit().RemoveOffset()
+func (pg PgString) ReplaceSelect(obj any) PgString {
+	sel := Select(obj)
+	if err := sel.Err(); err != nil {
+		return pg.fail(err)
+	}
+
+	pg.fields = sel.fields
+	pg.args = append(slices.Clip(pg.args), sel.args...)
+	if slices.ContainsFunc(pg.clauses, func(c clause) bool { return c.kind == clauseSelectDistinct }) {
+		// Keep DISTINCT when replacing the fields of a SELECT DISTINCT
+		c := sel.clauses[0]
+		c.kind = clauseSelectDistinct
+		return pg.replace(c, clauseFrom)
+	}
+	return pg.replace(sel.clauses[0], clauseFrom)
+}
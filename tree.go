@@ -0,0 +1,55 @@
+package pgstring
+
+import "strconv"
+
+// Tree generates recursive CTE queries over a hierarchy table whose rows reference their
+// parent by id. Built with TreeQuery.
+type Tree struct {
+	table    string
+	id       string
+	parent   string
+	maxDepth int
+}
+
+// TreeQuery creates a Tree for table, where parentCol holds the idCol of each row's parent
+func TreeQuery(table, idCol, parentCol string) Tree {
+	return Tree{table: table, id: idCol, parent: parentCol}
+}
+
+// MaxDepth stops the traversal n levels away from the starting row, which also guards
+// against cycles in the data
+func (t Tree) MaxDepth(n int) Tree {
+	t.maxDepth = n
+	return t
+}
+
+// Descendants selects the row with id and everything below it. Rows are selected from the
+// tree CTE with an extra depth column, 0 for the starting row, so the query can be refined:
+//
+//	TreeQuery("categories", "id", "parent_id").Descendants(7).Where("depth > 0").OrderBy("depth")
+func (t Tree) Descendants(id any) PgString {
+	return t.query(t.parent, t.id, id)
+}
+
+// Ancestors selects the row with id and every row above it up to the root
+func (t Tree) Ancestors(id any) PgString {
+	return t.query(t.id, t.parent, id)
+}
+
+// query joins each level's rows whose join column matches the previous level's match column
+func (t Tree) query(join, match string, id any) PgString {
+	recursive := "WITH RECURSIVE tree AS (" +
+		"SELECT " + t.table + ".*, 0 AS depth FROM " + t.table + " WHERE " + t.table + "." + t.id + " = @tree_id" +
+		" UNION ALL " +
+		"SELECT " + t.table + ".*, tree.depth + 1 FROM " + t.table + " JOIN tree ON " + t.table + "." + join + " = tree." + match
+	if t.maxDepth > 0 {
+		recursive += " WHERE tree.depth < " + strconv.Itoa(t.maxDepth)
+	}
+	recursive += ")"
+
+	return PgString{}.
+		with(clause{kind: clauseRaw, sql: recursive, table: t.table}).
+		with(clause{kind: clauseSelect, sql: "*"}).
+		with(clause{kind: clauseFrom, sql: "tree"}).
+		withArg("tree_id", id)
+}
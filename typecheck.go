@@ -0,0 +1,152 @@
+package pgstring
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CheckTypes makes Build check the Go type of every arg named after a column of model
+// against the column type model's tags declare (the type CreateTable would create),
+// catching e.g. a string bound to an INTEGER column before the server does. Args derived
+// from a column, like @id_in_0, @email_eq or @created_at_start, are checked against it
+// too; args of other names, nil values and driver.Valuer values aren't checked.
+func (pg PgString) CheckTypes(model any) PgString {
+	val, ok := structValue(model)
+	if !ok {
+		return pg.fail(errNotStruct)
+	}
+	pg.typeCheck = val.Type()
+	return pg
+}
+
+// derivedArg matches the suffixes the builders append to a column name to name its args
+var derivedArg = regexp.MustCompile(`_(in_)?\d+$|_(start|end|in|similar)$|_(pattern|eq)\d*$`)
+
+// checkArgTypes returns an error for the first arg whose type doesn't suit its column
+func (pg PgString) checkArgTypes(args map[string]any) error {
+	info := structInfoOf(pg.typeCheck)
+	columns := make(map[string]fieldInfo, len(info.fields))
+	for _, field := range info.fields {
+		columns[field.name] = field
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		field, ok := columns[name]
+		if !ok {
+			if field, ok = columns[derivedArg.ReplaceAllString(name, "")]; !ok {
+				continue
+			}
+		}
+		if !declaresType(field) || reflect.TypeOf(args[name]) == field.typ {
+			continue
+		}
+		sqlType, _, isArray := columnType(field.typ, field.options)
+		if !argFits(args[name], sqlType, isArray) {
+			return fmt.Errorf("arg @%s: %T doesn't match column %s %s", name, args[name], field.name, sqlType)
+		}
+	}
+	return nil
+}
+
+// declaresType reports whether the field's Go type maps to a specific column type, rather
+// than the TEXT fallback CreateTable uses for types it doesn't know
+func declaresType(field fieldInfo) bool {
+	if hasOption(field.options, "jsonb") {
+		return true
+	}
+	if field.transform != "" {
+		// The bound value is whatever the transformer returns
+		return false
+	}
+	typ := field.typ
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if elem, ok := nullableElem(typ); ok {
+		typ = elem
+	}
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	if _, ok := pgtypeColumn(typ); ok {
+		return true
+	}
+	return typ == reflect.TypeOf(time.Time{})
+}
+
+// argFits reports whether value can be bound to a column of sqlType. Slices are accepted
+// for scalar columns as long as their elements fit, for = ANY(@arg) comparisons.
+func argFits(value any, sqlType string, isArray bool) bool {
+	if value == nil {
+		return true
+	}
+	if _, ok := value.(driver.Valuer); ok {
+		return true
+	}
+
+	val := reflect.ValueOf(value)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return true
+		}
+		val = val.Elem()
+	}
+
+	baseType := strings.TrimSuffix(sqlType, "[]")
+	if val.Kind() == reflect.Slice || val.Kind() == reflect.Array {
+		if val.Type().Elem().Kind() == reflect.Uint8 || baseType == "JSON" || baseType == "JSONB" {
+			return true
+		}
+		return kindFits(val.Type().Elem(), baseType)
+	}
+	if isArray {
+		return false
+	}
+	return kindFits(val.Type(), baseType)
+}
+
+// kindFits reports whether values of typ suit a scalar column of sqlType
+func kindFits(typ reflect.Type, sqlType string) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch sqlType {
+	case "JSON", "JSONB":
+		return true
+	case "BOOLEAN":
+		return typ.Kind() == reflect.Bool
+	case "SMALLINT", "INTEGER", "BIGINT":
+		return isInteger(typ.Kind())
+	case "REAL", "DOUBLE PRECISION":
+		return isInteger(typ.Kind()) || typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64
+	case "NUMERIC":
+		return isInteger(typ.Kind()) || typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64 || typ.Kind() == reflect.String
+	case "TIMESTAMP", "TIMESTAMPTZ", "DATE":
+		return typ == reflect.TypeOf(time.Time{}) || typ.Kind() == reflect.String
+	case "BYTEA", "HSTORE", "POINT", "INTERVAL":
+		// Bound by pgx from Go types of their own, e.g. time.Duration for INTERVAL
+		return true
+	default:
+		// TEXT and enum types
+		return typ.Kind() == reflect.String
+	}
+}
+
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
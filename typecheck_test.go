@@ -0,0 +1,46 @@
+package pgstring_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+type account struct {
+	ID        int       `db:"id,primarykey"`
+	Email     string    `db:"email"`
+	Active    bool      `db:"active"`
+	Tags      []string  `db:"tags"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func TestCheckTypesMismatch(t *testing.T) {
+	q := pgstring.Select("*").From("accounts").CheckTypes(account{})
+	assertErr(t, q.Where("id = @id", map[string]any{"id": "7"}), "arg @id: string doesn't match column id INTEGER")
+	assertErr(t, q.Where("active = @active", map[string]any{"active": 1}), "arg @active: int doesn't match column active BOOLEAN")
+	assertErr(t, q.Where("tags = @tags", map[string]any{"tags": "a"}), "arg @tags: string doesn't match column tags TEXT[]")
+	assertErr(t, q.In("id", []string{"1", "2"}), "arg @id_in_0: string doesn't match column id INTEGER")
+	assertErr(t, q.Between("created_at", 1, 2), "arg @created_at_end: int doesn't match column created_at TIMESTAMP")
+}
+
+func TestCheckTypesAccepts(t *testing.T) {
+	id := 7
+	assertSQL(t, pgstring.Select("*").From("accounts").CheckTypes(account{}).
+		Where("id = @id AND email = @email", map[string]any{"id": &id, "email": sql.NullString{}}).
+		Where("id = ANY(@ids) AND active = @active", map[string]any{"ids": []int64{1}, "active": nil}).
+		Where("created_at > @since AND other = @other", map[string]any{"since": "2024-01-01", "other": 1.5}),
+		"SELECT * FROM accounts WHERE (id = @id AND email = @email) AND (id = ANY(@ids) AND active = @active) AND (created_at > @since AND other = @other)",
+		map[string]any{"id": &id, "email": sql.NullString{}, "ids": []int64{1}, "active": nil, "since": "2024-01-01", "other": 1.5})
+}
+
+func TestCheckTypesNotStruct(t *testing.T) {
+	assertErr(t, pgstring.Select("*").From("accounts").CheckTypes(1), "struct")
+}
+
+func TestCheckTypesDerivedArgs(t *testing.T) {
+	q := pgstring.Select("*").From("accounts").CheckTypes(account{})
+	assertErr(t, q.Like("id", "1%"), "arg @id_pattern: string doesn't match column id INTEGER")
+	assertErr(t, q.WhereEqCI("id", "7"), "arg @id_eq: string doesn't match column id INTEGER")
+}
@@ -0,0 +1,75 @@
+package pgstring
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ColumnMismatch lists the columns and fields ValuesFrom left out of an INSERT
+type ColumnMismatch struct {
+	// Columns are columns of the Obj list without a field in the Values struct
+	Columns []string
+	// Fields are fields of the Values struct that aren't in the Obj list
+	Fields []string
+}
+
+// ValuesFrom is like Values but accepts a struct with a different set of fields than the
+// one passed to Obj, e.g. a request DTO inserted into a model's table. Only the columns both
+// structs have are inserted; Unmatched reports the rest. Having no column in common is an error.
+func (pg PgString) ValuesFrom(obj any) PgString {
+	val, ok := structValue(obj)
+	if !ok {
+		return pg.fail(errNotStruct)
+	}
+	if len(pg.fields) == 0 {
+		return pg.fail(errors.New("ValuesFrom: no column list, call Obj first"))
+	}
+	obj, err := beforeInsert(obj)
+	if err != nil {
+		return pg.fail(err)
+	}
+
+	fields := structInfoOf(val.Type()).writable
+	var matched []string
+	var mismatch ColumnMismatch
+	for _, column := range pg.fields {
+		if slices.Contains(fields, column) {
+			matched = append(matched, column)
+		} else {
+			mismatch.Columns = append(mismatch.Columns, column)
+		}
+	}
+	for _, field := range fields {
+		if !slices.Contains(pg.fields, field) {
+			mismatch.Fields = append(mismatch.Fields, field)
+		}
+	}
+	if len(matched) == 0 {
+		return pg.fail(fmt.Errorf("ValuesFrom(%s): no fields match the column list", val.Type()))
+	}
+
+	namedArgs, err := extractNamedArgs(obj, pg.nullPolicy)
+	if err != nil {
+		return pg.fail(err)
+	}
+	for _, arg := range namedArgs {
+		if slices.Contains(matched, arg.name) {
+			pg.args = append(slices.Clip(pg.args), arg)
+		}
+	}
+
+	// Narrow the column list Obj added to the matched columns
+	if i := slices.IndexFunc(pg.clauses, func(c clause) bool { return c.kind == clauseColumns }); i >= 0 {
+		pg.clauses = slices.Clone(pg.clauses)
+		pg.clauses[i].fields = matched
+	}
+	pg.fields = matched
+	pg.unmatched = mismatch
+	return pg.with(clause{kind: clauseValues, fields: matched})
+}
+
+// Unmatched returns the columns and fields ValuesFrom left out
+func (pg PgString) Unmatched() ColumnMismatch {
+	return pg.unmatched
+}
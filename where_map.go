@@ -0,0 +1,91 @@
+package pgstring
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// whereOps maps the operators WhereEq accepts in keys to the suffix of their arg name. Every
+// operator has one, so the args never collide with the @column args of Set or Values.
+var whereOps = map[string]string{
+	"=": "_eq", "<>": "_ne", "!=": "_ne", "<": "_lt", "<=": "_lte", ">": "_gt", ">=": "_gte",
+	"LIKE": "_like", "ILIKE": "_ilike", "IN": "_in", "NOT IN": "_not_in",
+}
+
+// WhereEq adds a WHERE condition per entry of conditions, joined with AND, for quick ad-hoc
+// filters with bound args. Keys are a column, optionally qualified, and an optional
+// operator: =, <>, !=, <, <=, >, >=, LIKE, ILIKE, IN or NOT IN. Without an operator the
+// column must equal the value; nil values test IS NULL (or IS NOT NULL for <> and !=), and
+// IN and NOT IN take a slice, bound as an array. Entries are added sorted by key. Args are
+// named after the column and operator, numbered when the query already binds the name.
+//
+//	WhereEq(map[string]any{"age >=": 18, "status": "active", "role in": []string{"admin", "owner"}})
+//	// WHERE (age >= @age_gte) AND (role = ANY(@role_in)) AND (status = @status_eq)
+func (pg PgString) WhereEq(conditions map[string]any) PgString {
+	for _, key := range slices.Sorted(maps.Keys(conditions)) {
+		column, op, err := parseWhereKey(key)
+		if err != nil {
+			return pg.fail(fmt.Errorf("WhereEq: %w", err))
+		}
+		value := conditions[key]
+		name := argName(column) + whereOps[op]
+		for n := 2; pg.hasArg(name); n++ {
+			name = argName(column) + whereOps[op] + strconv.Itoa(n)
+		}
+		column = quoteQualified(column)
+
+		var condition string
+		switch {
+		case value == nil && (op == "=" || op == "<>" || op == "!="):
+			condition = column + " IS NULL"
+			if op != "=" {
+				condition = column + " IS NOT NULL"
+			}
+			pg = pg.with(clause{kind: clauseWhere, sql: condition})
+			continue
+		case op == "IN" || op == "NOT IN":
+			if kind := reflect.ValueOf(value).Kind(); kind != reflect.Slice && kind != reflect.Array {
+				return pg.fail(fmt.Errorf("WhereEq: %s needs a slice, got %T", key, value))
+			}
+			condition = column + " = ANY(@" + name + ")"
+			if op == "NOT IN" {
+				condition = "NOT (" + column + " = ANY(@" + name + "))"
+			}
+		default:
+			condition = column + " " + op + " @" + name
+		}
+		pg = pg.with(clause{kind: clauseWhere, sql: condition}).withArg(name, value)
+	}
+	return pg
+}
+
+// parseWhereKey splits a WhereEq key into its column and upper-cased operator
+func parseWhereKey(key string) (string, string, error) {
+	column, op, _ := strings.Cut(strings.TrimSpace(key), " ")
+	op = strings.ToUpper(strings.Join(strings.Fields(op), " "))
+	if op == "" {
+		op = "="
+	}
+	if _, ok := whereOps[op]; !ok {
+		return "", "", fmt.Errorf("unsupported operator %q in %q", op, key)
+	}
+	for _, part := range strings.Split(column, ".") {
+		if part == "" || !isIdentStart(part[0]) || strings.IndexFunc(part, func(r rune) bool { return r > 127 || !isIdentChar(byte(r)) }) >= 0 {
+			return "", "", fmt.Errorf("invalid column %q", column)
+		}
+	}
+	return column, op, nil
+}
+
+// quoteQualified quotes the reserved words of a possibly qualified column, e.g. o."order"
+func quoteQualified(column string) string {
+	parts := strings.Split(column, ".")
+	for i, part := range parts {
+		parts[i] = quoteColumn(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,47 @@
+package pgstring_test
+
+import (
+	"testing"
+
+	"github.com/oliverpaddock/pgstring"
+)
+
+func TestWhereEq(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").WhereEq(map[string]any{
+		"age >=":     18,
+		"status":     "active",
+		"role in":    []string{"admin", "owner"},
+		"deleted_at": nil,
+		"u.order <>": 3,
+	}),
+		`SELECT * FROM users WHERE (age >= @age_gte) AND (deleted_at IS NULL) AND (role = ANY(@role_in)) AND (status = @status_eq) AND (u."order" <> @u_order_ne)`,
+		map[string]any{"age_gte": 18, "role_in": []string{"admin", "owner"}, "status_eq": "active", "u_order_ne": 3})
+}
+
+func TestWhereEqSingle(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").WhereEq(map[string]any{"email not in": []string{"a"}}),
+		"SELECT * FROM users WHERE NOT (email = ANY(@email_not_in))",
+		map[string]any{"email_not_in": []string{"a"}})
+}
+
+func TestWhereEqWithSet(t *testing.T) {
+	type account struct {
+		Status string `db:"status"`
+	}
+	assertSQL(t, pgstring.Update("users").Set(account{Status: "new"}).WhereEq(map[string]any{"status": "old"}),
+		"UPDATE users SET status = @status WHERE status = @status_eq",
+		map[string]any{"status": "new", "status_eq": "old"})
+}
+
+func TestWhereEqTwice(t *testing.T) {
+	assertSQL(t, pgstring.Select("*").From("users").WhereEq(map[string]any{"id": 1}).WhereEq(map[string]any{"id": 2}),
+		"SELECT * FROM users WHERE (id = @id_eq) AND (id = @id_eq2)",
+		map[string]any{"id_eq": 1, "id_eq2": 2})
+}
+
+func TestWhereEqErrors(t *testing.T) {
+	assertErr(t, pgstring.Select("*").From("users").WhereEq(map[string]any{"id ~": 1}), "unsupported operator")
+	assertErr(t, pgstring.Select("*").From("users").WhereEq(map[string]any{"id; DROP": 1}), "unsupported operator")
+	assertErr(t, pgstring.Select("*").From("users").WhereEq(map[string]any{"1id": 1}), "invalid column")
+	assertErr(t, pgstring.Select("*").From("users").WhereEq(map[string]any{"id in": 1}), "needs a slice")
+}